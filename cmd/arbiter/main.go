@@ -5,16 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
 	"github.com/viasnake/arbiter/internal/app"
 	"github.com/viasnake/arbiter/internal/audit"
 	"github.com/viasnake/arbiter/internal/authz"
 	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/grpcapi"
 	"github.com/viasnake/arbiter/internal/httpapi"
 	"github.com/viasnake/arbiter/internal/planner"
 	"github.com/viasnake/arbiter/internal/store"
@@ -31,6 +35,10 @@ func main() {
 		if err := runServe(os.Args[2:]); err != nil {
 			log.Fatalf("serve failed: %v", err)
 		}
+	case "migrate":
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("migrate failed: %v", err)
+		}
 	default:
 		printUsage()
 		os.Exit(2)
@@ -49,13 +57,20 @@ func runServe(args []string) error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	st := store.NewMemoryStore()
+	st, err := newStore(cfg.Store)
+	if err != nil {
+		return fmt.Errorf("init store: %w", err)
+	}
+	if closer, ok := st.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+
 	az := authz.NewProvider(cfg.Authz)
-	pl := planner.New(cfg.Planner)
+	pl := planner.New(cfg.Planner, st)
 
-	auditor, err := audit.NewJSONLLogger(cfg.Audit.JSONLPath)
+	auditor, err := audit.NewMultiplexerFromConfig(cfg.Audit.Sinks)
 	if err != nil {
-		return fmt.Errorf("init audit logger: %w", err)
+		return fmt.Errorf("init audit sinks: %w", err)
 	}
 	defer auditor.Close()
 
@@ -68,6 +83,22 @@ func runServe(args []string) error {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCListenAddr != "" {
+		grpcTimeout := time.Duration(cfg.Server.GRPCRequestTimeoutMS) * time.Millisecond
+		grpcServer = grpcapi.NewGRPCServer(svc, auditor, st, cfg.Gate.TenantRateLimitPerMin, grpcTimeout)
+		lis, err := net.Listen("tcp", cfg.Server.GRPCListenAddr)
+		if err != nil {
+			return fmt.Errorf("listen grpc: %w", err)
+		}
+		go func() {
+			log.Printf("arbiter grpc listening on %s", cfg.Server.GRPCListenAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Printf("grpc server stopped: %v", err)
+			}
+		}()
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
@@ -76,6 +107,9 @@ func runServe(args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		_ = httpServer.Shutdown(ctx)
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
 	}()
 
 	log.Printf("arbiter listening on %s", cfg.Server.ListenAddr)
@@ -85,6 +119,63 @@ func runServe(args []string) error {
 	return nil
 }
 
+func newStore(cfg config.StoreConfig) (store.Store, error) {
+	switch cfg.Type {
+	case "sqlite":
+		ttl := time.Duration(cfg.IdempotencyTTLSeconds) * time.Second
+		return store.NewSQLiteStore(cfg.SQLitePath, ttl)
+	case "redis":
+		ttl := time.Duration(cfg.IdempotencyTTLSeconds) * time.Second
+		return store.NewRedisStore(cfg.Redis, ttl)
+	case "postgres":
+		ttl := time.Duration(cfg.IdempotencyTTLSeconds) * time.Second
+		return store.NewPostgresStore(cfg.Postgres, ttl)
+	case "memory", "":
+		return store.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported store.type %q", cfg.Type)
+	}
+}
+
+// runMigrate applies the configured store's outstanding migrations and
+// exits, without starting the server. NewSQLiteStore/NewPostgresStore
+// already migrate on open, so this is just "open, then close" -- useful for
+// running migrations as a separate deploy step ahead of rolling out new
+// arbiter instances.
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	cfgPath := fs.String("config", "./config/example-config.yaml", "path to config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	switch cfg.Store.Type {
+	case "sqlite":
+		st, err := store.NewSQLiteStore(cfg.Store.SQLitePath, 0)
+		if err != nil {
+			return fmt.Errorf("migrate sqlite store: %w", err)
+		}
+		defer st.Close()
+	case "postgres":
+		st, err := store.NewPostgresStore(cfg.Store.Postgres, 0)
+		if err != nil {
+			return fmt.Errorf("migrate postgres store: %w", err)
+		}
+		defer st.Close()
+	default:
+		return fmt.Errorf("store.type %q has no migrations to run", cfg.Store.Type)
+	}
+
+	log.Printf("store.type %q is up to date", cfg.Store.Type)
+	return nil
+}
+
 func printUsage() {
 	fmt.Fprintln(os.Stderr, "Usage: arbiter serve --config ./config/example-config.yaml")
+	fmt.Fprintln(os.Stderr, "       arbiter migrate --config ./config/example-config.yaml")
 }
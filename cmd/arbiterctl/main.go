@@ -0,0 +1,58 @@
+// Command arbiterctl is an operator CLI for offline maintenance tasks
+// against arbiter's on-disk state, starting with audit chain verification.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/viasnake/arbiter/internal/audit"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "audit":
+		if err := runAudit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "arbiterctl: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+func runAudit(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: arbiterctl audit verify --path <audit.jsonl>")
+	}
+
+	switch args[0] {
+	case "verify":
+		fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+		path := fs.String("path", "", "path to the audit JSONL file")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *path == "" {
+			return fmt.Errorf("--path is required")
+		}
+		if err := audit.Verify(*path); err != nil {
+			return fmt.Errorf("chain verification failed: %w", err)
+		}
+		fmt.Printf("audit chain at %s verified ok\n", *path)
+		return nil
+	default:
+		return fmt.Errorf("unknown audit subcommand %q", args[0])
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: arbiterctl audit verify --path <audit.jsonl>")
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// planSubscriberBuffer bounds how many plans a single subscriber can lag
+// behind before new plans are dropped for it rather than blocking the
+// goroutine that produced them.
+const planSubscriberBuffer = 16
+
+// streamSubscriberBuffer is streamSub's analog of planSubscriberBuffer: how
+// much a subscriber may lag behind live events (after resume replay has
+// finished) before new ones are dropped for it.
+const streamSubscriberBuffer = 32
+
+// streamRingSize bounds how many StreamEvents are kept per tenant for
+// Last-Event-ID resume; an SSE client reconnecting after a longer gap than
+// this gets only the streamRingSize most recent events, and the older ones
+// it missed are lost.
+const streamRingSize = 256
+
+// streamChannelBuffer sizes a subscriber's channel to fit a full
+// streamRingSize replay plus streamSubscriberBuffer of headroom for live
+// events, so a client resuming after missing up to streamRingSize events
+// never has replayed events dropped by the same default-drop send the live
+// path uses.
+const streamChannelBuffer = streamRingSize + streamSubscriberBuffer
+
+// StreamEventKind discriminates the two kinds of events httpapi's SSE plan
+// stream delivers.
+type StreamEventKind string
+
+const (
+	StreamEventPlan         StreamEventKind = "plan"
+	StreamEventActionResult StreamEventKind = "action_result"
+)
+
+// ActionResultEvent mirrors what POST /v0/action-results persisted to the
+// audit log, republished on the same stream as the plan it closes out so a
+// subscriber can observe the correlation without a second round trip.
+type ActionResultEvent struct {
+	TenantID      string `json:"tenant_id"`
+	CorrelationID string `json:"correlation_id"`
+	ReasonCode    string `json:"reason_code,omitempty"`
+}
+
+// StreamEvent is one item on the SSE plan-delivery stream: either a
+// ResponsePlan the service just produced, or an ActionResultEvent
+// correlating a previously-delivered plan's outcome. ID is a
+// per-tenant-stream monotonic sequence number usable as an SSE id/
+// Last-Event-ID.
+type StreamEvent struct {
+	ID           uint64
+	TenantID     string
+	RoomID       string
+	Kind         StreamEventKind
+	Plan         *domain.ResponsePlan
+	ActionResult *ActionResultEvent
+}
+
+// StreamSubscription is a live, resumable feed of StreamEvent for one
+// tenant, optionally filtered to one room. Callers must invoke Cancel once
+// they stop reading to release the subscriber slot and close the channel.
+type StreamSubscription struct {
+	Events <-chan StreamEvent
+	Cancel func()
+}
+
+type streamSub struct {
+	ch     chan StreamEvent
+	roomID string
+}
+
+// PlanSubscription is a live feed of domain.ResponsePlan for one tenant.
+// Callers must invoke Cancel once they stop reading to release the
+// subscriber slot and close the channel.
+type PlanSubscription struct {
+	Plans  <-chan domain.ResponsePlan
+	Cancel func()
+}
+
+// planBroker fans out every ResponsePlan the service produces to
+// subscribers keyed by tenant, so transports like grpcapi's WatchPlans RPC
+// can push plans to callers the moment they're produced instead of making
+// callers poll. It also fans out a richer StreamEvent feed (plans plus
+// action-result correlations, with Last-Event-ID resume) for httpapi's SSE
+// plan stream.
+type planBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan domain.ResponsePlan]struct{}
+
+	streamSubs map[string]map[*streamSub]struct{}
+	streamRing map[string][]StreamEvent
+	streamSeq  uint64
+}
+
+func newPlanBroker() *planBroker {
+	return &planBroker{
+		subs:       make(map[string]map[chan domain.ResponsePlan]struct{}),
+		streamSubs: make(map[string]map[*streamSub]struct{}),
+		streamRing: make(map[string][]StreamEvent),
+	}
+}
+
+func (b *planBroker) subscribe(tenantID string) PlanSubscription {
+	ch := make(chan domain.ResponsePlan, planSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[tenantID] == nil {
+		b.subs[tenantID] = make(map[chan domain.ResponsePlan]struct{})
+	}
+	b.subs[tenantID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[tenantID], ch)
+			if len(b.subs[tenantID]) == 0 {
+				delete(b.subs, tenantID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return PlanSubscription{Plans: ch, Cancel: cancel}
+}
+
+// publish pushes plan to every live subscriber of plan.TenantID. A
+// subscriber whose buffer is full is skipped rather than blocking the
+// caller that produced the plan.
+func (b *planBroker) publish(plan domain.ResponsePlan) {
+	b.mu.Lock()
+	for ch := range b.subs[plan.TenantID] {
+		select {
+		case ch <- plan:
+		default:
+		}
+	}
+	b.mu.Unlock()
+
+	b.publishStream(StreamEvent{
+		TenantID: plan.TenantID,
+		RoomID:   plan.RoomID,
+		Kind:     StreamEventPlan,
+		Plan:     &plan,
+	})
+}
+
+// subscribeStream registers a new SSE-style subscriber for tenantID,
+// optionally filtered to roomID (empty matches every room). Every buffered
+// StreamEvent with an ID greater than lastEventID is replayed into the
+// subscriber's channel before live events start arriving, so a reconnecting
+// client can resume from where it left off; a zero lastEventID replays the
+// tenant's whole buffered backlog. The channel is
+// sized to fit a full ring's worth of replay (streamChannelBuffer), so a
+// client resuming after missing anywhere up to streamRingSize events gets
+// all of them rather than just the first streamSubscriberBuffer.
+func (b *planBroker) subscribeStream(tenantID, roomID string, lastEventID uint64) StreamSubscription {
+	sub := &streamSub{ch: make(chan StreamEvent, streamChannelBuffer), roomID: roomID}
+
+	b.mu.Lock()
+	for _, ev := range b.streamRing[tenantID] {
+		if ev.ID > lastEventID && matchesRoom(ev, roomID) {
+			select {
+			case sub.ch <- ev:
+			default:
+			}
+		}
+	}
+	if b.streamSubs[tenantID] == nil {
+		b.streamSubs[tenantID] = make(map[*streamSub]struct{})
+	}
+	b.streamSubs[tenantID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.streamSubs[tenantID], sub)
+			if len(b.streamSubs[tenantID]) == 0 {
+				delete(b.streamSubs, tenantID)
+			}
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+	return StreamSubscription{Events: sub.ch, Cancel: cancel}
+}
+
+// publishStream assigns ev the next sequence ID, records it in its tenant's
+// ring buffer, and fans it out to every matching live subscriber for that
+// tenant. A subscriber whose buffer is full is skipped rather than blocking
+// the caller.
+func (b *planBroker) publishStream(ev StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.streamSeq++
+	ev.ID = b.streamSeq
+
+	ring := append(b.streamRing[ev.TenantID], ev)
+	if len(ring) > streamRingSize {
+		ring = ring[len(ring)-streamRingSize:]
+	}
+	b.streamRing[ev.TenantID] = ring
+
+	for sub := range b.streamSubs[ev.TenantID] {
+		if !matchesRoom(ev, sub.roomID) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+		}
+	}
+}
+
+// publishActionResult republishes a recorded action result onto the
+// tenant's stream so an SSE subscriber that received the originating plan
+// can observe its closure without a second round trip.
+func (b *planBroker) publishActionResult(tenantID, correlationID, reasonCode string) {
+	b.publishStream(StreamEvent{
+		TenantID: tenantID,
+		Kind:     StreamEventActionResult,
+		ActionResult: &ActionResultEvent{
+			TenantID:      tenantID,
+			CorrelationID: correlationID,
+			ReasonCode:    reasonCode,
+		},
+	})
+}
+
+// matchesRoom reports whether ev should be delivered to a subscriber
+// filtered to roomID. An empty roomID matches everything. Action-result
+// events carry no room attribution (POST /v0/action-results has no
+// room_id), so they always match a room-filtered subscriber too -- the
+// correlation they carry would otherwise never reach it.
+func matchesRoom(ev StreamEvent, roomID string) bool {
+	if roomID == "" || ev.Kind == StreamEventActionResult {
+		return true
+	}
+	return ev.RoomID == roomID
+}
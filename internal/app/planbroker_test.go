@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+func TestPlanBrokerSubscribeStreamReplaysAllBufferedEventsOnResume(t *testing.T) {
+	b := newPlanBroker()
+
+	const published = streamRingSize - 1
+	for i := 0; i < published; i++ {
+		b.publishStream(StreamEvent{TenantID: "t1", Kind: StreamEventPlan, Plan: &domain.ResponsePlan{TenantID: "t1"}})
+	}
+
+	sub := b.subscribeStream("t1", "", 0)
+	defer sub.Cancel()
+
+	got := 0
+	var lastID uint64
+	for i := 0; i < published; i++ {
+		ev := <-sub.Events
+		got++
+		if ev.ID <= lastID {
+			t.Fatalf("expected strictly increasing event IDs, got %d after %d", ev.ID, lastID)
+		}
+		lastID = ev.ID
+	}
+	if got != published {
+		t.Fatalf("expected all %d buffered events replayed, got %d", published, got)
+	}
+}
+
+func TestPlanBrokerSubscribeStreamResumesFromLastEventID(t *testing.T) {
+	b := newPlanBroker()
+
+	var ids []uint64
+	for i := 0; i < 5; i++ {
+		b.publishStream(StreamEvent{TenantID: "t1", Kind: StreamEventPlan, Plan: &domain.ResponsePlan{TenantID: "t1"}})
+	}
+	b.mu.Lock()
+	for _, ev := range b.streamRing["t1"] {
+		ids = append(ids, ev.ID)
+	}
+	b.mu.Unlock()
+
+	sub := b.subscribeStream("t1", "", ids[2])
+	defer sub.Cancel()
+
+	for _, want := range ids[3:] {
+		ev := <-sub.Events
+		if ev.ID != want {
+			t.Fatalf("expected replayed event ID %d, got %d", want, ev.ID)
+		}
+	}
+
+	select {
+	case ev := <-sub.Events:
+		t.Fatalf("expected no more buffered events, got %+v", ev)
+	default:
+	}
+}
@@ -16,26 +16,62 @@ import (
 
 type Service struct {
 	cfg     config.Config
-	store   *store.MemoryStore
+	store   store.Store
 	gate    *gate.Evaluator
 	authz   authz.Provider
-	planner *planner.Engine
+	planner planner.Provider
 	audit   audit.Logger
+	plans   *planBroker
 	nowFn   func() time.Time
 }
 
-func NewService(cfg config.Config, st *store.MemoryStore, az authz.Provider, pl *planner.Engine, al audit.Logger) *Service {
+func NewService(cfg config.Config, st store.Store, az authz.Provider, pl planner.Provider, al audit.Logger) *Service {
 	return &Service{
 		cfg:     cfg,
 		store:   st,
-		gate:    gate.NewEvaluator(cfg.Gate),
+		gate:    gate.NewEvaluator(cfg.Gate, st),
 		authz:   az,
 		planner: pl,
 		audit:   al,
+		plans:   newPlanBroker(),
 		nowFn:   func() time.Time { return time.Now().UTC() },
 	}
 }
 
+// WatchPlans subscribes to every ResponsePlan the service produces for
+// tenantID from this point forward. Callers must Cancel the subscription
+// once they stop reading.
+func (s *Service) WatchPlans(tenantID string) PlanSubscription {
+	return s.plans.subscribe(tenantID)
+}
+
+// WatchPlanStream subscribes to tenantID's SSE-style event stream (plans
+// plus action-result correlations), optionally filtered to roomID. Buffered
+// events newer than lastEventID are replayed first so an httpapi client
+// reconnecting with Last-Event-ID can resume without gaps; a zero
+// lastEventID replays the whole buffered backlog. Callers must Cancel the
+// subscription once they stop reading.
+func (s *Service) WatchPlanStream(tenantID, roomID string, lastEventID uint64) StreamSubscription {
+	return s.plans.subscribeStream(tenantID, roomID, lastEventID)
+}
+
+// auditMetricsProvider is implemented by audit.Multiplexer. It's declared
+// here rather than imported so transports can surface queue back-pressure
+// (e.g. under /v0/healthz) without this package depending on audit's
+// internal queue type beyond the Logger interface it already takes.
+type auditMetricsProvider interface {
+	Metrics() map[string]audit.QueueMetrics
+}
+
+// AuditMetrics reports per-sink audit queue depth/drop/retry counters, or
+// nil if the configured audit.Logger doesn't expose them.
+func (s *Service) AuditMetrics() map[string]audit.QueueMetrics {
+	if mp, ok := s.audit.(auditMetricsProvider); ok {
+		return mp.Metrics()
+	}
+	return nil
+}
+
 func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.ResponsePlan, error) {
 	if err := ev.Validate(); err != nil {
 		return domain.ResponsePlan{}, err
@@ -64,9 +100,12 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 
 	minuteBucket := eventTime.Unix() / 60
 	tenantCount := s.store.TenantRateCount(ev.TenantID, minuteBucket)
-	gateResult := s.gate.Evaluate(room, eventTime, tenantCount)
+	gateResult := s.gate.Evaluate(room, eventTime, tenantCount, ev.TenantID, ev.RoomID, ev.Actor.ID)
 	if !gateResult.Allowed {
 		plan := domain.DoNothingPlan(ev.TenantID, ev.RoomID, ev.EventID, gateResult.ReasonCode)
+		if gateResult.RetryAfter > 0 {
+			plan.Actions[0].Payload["retry_after_ms"] = gateResult.RetryAfter.Milliseconds()
+		}
 		s.store.PutIdempotency(ev.TenantID, ev.EventID, plan)
 		_ = s.audit.Append(audit.Record{
 			AuditID:       domain.NewActionID(plan.PlanID, "audit", 0),
@@ -78,6 +117,7 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 			TS:            s.nowFn().Format(time.RFC3339Nano),
 			PlanID:        plan.PlanID,
 		})
+		s.plans.publish(plan)
 		return plan, nil
 	}
 
@@ -95,14 +135,23 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 			TS:            s.nowFn().Format(time.RFC3339Nano),
 			PlanID:        plan.PlanID,
 		})
+		s.plans.publish(plan)
 		return plan, nil
 	}
 
-	intent := s.planner.Decide(ev)
+	intent, plannerMeta, err := s.planner.Decide(ctx, ev)
+	if err != nil {
+		return domain.ResponsePlan{}, err
+	}
+
 	var plan domain.ResponsePlan
 	switch intent {
 	case planner.IntentIgnore:
-		plan = domain.DoNothingPlan(ev.TenantID, ev.RoomID, ev.EventID, "planner_ignore")
+		reason := plannerMeta.ReasonCode
+		if reason == "" {
+			reason = "planner_ignore"
+		}
+		plan = domain.DoNothingPlan(ev.TenantID, ev.RoomID, ev.EventID, reason)
 	case planner.IntentReply, planner.IntentMessage:
 		plan = domain.ResponsePlan{
 			V:        domain.ContractVersion,
@@ -126,7 +175,7 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 			PolicyDecisions: []domain.PolicyDecision{
 				{Stage: "gate", Result: "allow"},
 				{Stage: "authz", Result: "allow", ReasonCode: authzDecision.ReasonCode},
-				{Stage: "planner", Result: "allow", ReasonCode: string(intent)},
+				{Stage: "planner", Result: "allow", ReasonCode: plannerMeta.ReasonCode, PolicyVersion: plannerMeta.PolicyVersion},
 			},
 		}
 		action := plan.Actions[0]
@@ -139,7 +188,11 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 			ReplyTo:  ev.Content.ReplyTo,
 		})
 	default:
-		plan = domain.DoNothingPlan(ev.TenantID, ev.RoomID, ev.EventID, "planner_unknown")
+		reason := plannerMeta.ReasonCode
+		if reason == "" {
+			reason = "planner_unknown"
+		}
+		plan = domain.DoNothingPlan(ev.TenantID, ev.RoomID, ev.EventID, reason)
 	}
 
 	s.store.IncrementTenantRate(ev.TenantID, minuteBucket)
@@ -151,9 +204,11 @@ func (s *Service) ProcessEvent(ctx context.Context, ev domain.Event) (domain.Res
 		Action:        "process_event",
 		Result:        "ok",
 		ReasonCode:    plan.Actions[0].Type,
+		PolicyVersion: plannerMeta.PolicyVersion,
 		TS:            s.nowFn().Format(time.RFC3339Nano),
 		PlanID:        plan.PlanID,
 	})
+	s.plans.publish(plan)
 	return plan, nil
 }
 
@@ -175,6 +230,7 @@ func (s *Service) ProcessGeneration(_ context.Context, result domain.GenerationR
 			TS:            s.nowFn().Format(time.RFC3339Nano),
 			PlanID:        plan.PlanID,
 		})
+		s.plans.publish(plan)
 		return plan, nil
 	}
 
@@ -216,6 +272,7 @@ func (s *Service) ProcessGeneration(_ context.Context, result domain.GenerationR
 		TS:            s.nowFn().Format(time.RFC3339Nano),
 		PlanID:        plan.PlanID,
 	})
+	s.plans.publish(plan)
 	return plan, nil
 }
 
@@ -223,7 +280,7 @@ func (s *Service) RecordActionResult(tenantID, correlationID, reason string) err
 	if tenantID == "" || correlationID == "" {
 		return errors.New("tenant_id and correlation_id are required")
 	}
-	return s.audit.Append(audit.Record{
+	if err := s.audit.Append(audit.Record{
 		AuditID:       domain.NewActionID(correlationID, "audit", 0),
 		TenantID:      tenantID,
 		CorrelationID: correlationID,
@@ -231,5 +288,9 @@ func (s *Service) RecordActionResult(tenantID, correlationID, reason string) err
 		Result:        "recorded",
 		ReasonCode:    reason,
 		TS:            s.nowFn().Format(time.RFC3339Nano),
-	})
+	}); err != nil {
+		return err
+	}
+	s.plans.publishActionResult(tenantID, correlationID, reason)
+	return nil
 }
@@ -54,11 +54,11 @@ func baseEvent(id string) domain.Event {
 	}
 }
 
-func newServiceForTest(t *testing.T, cfg config.Config, p authz.Provider) (*Service, *store.MemoryStore, *memoryAudit) {
+func newServiceForTest(t *testing.T, cfg config.Config, p authz.Provider) (*Service, store.Store, *memoryAudit) {
 	t.Helper()
 	st := store.NewMemoryStore()
 	al := &memoryAudit{}
-	svc := NewService(cfg, st, p, planner.New(cfg.Planner), al)
+	svc := NewService(cfg, st, p, planner.New(cfg.Planner, st), al)
 	return svc, st, al
 }
 
@@ -156,3 +156,47 @@ func TestGenerationResultProducesSendReply(t *testing.T) {
 		t.Fatalf("expected send_reply, got %s", out.Actions[0].Type)
 	}
 }
+
+func TestProcessEventRateLimitScopeDeniesWithRetryAfter(t *testing.T) {
+	cfg := config.Default()
+	cfg.Planner.ReplyPolicy = "all"
+	cfg.Gate.CooldownMS = 0
+	cfg.Gate.RateLimit.Scopes = []config.RateLimitScopeConfig{
+		{Name: "per-room", Scope: "tenant:room", Kind: "token_bucket", RatePerSec: 0.01, Burst: 1},
+	}
+
+	provider := &fixedProvider{d: authz.Decision{Allow: true, ReasonCode: "ok"}}
+	svc, _, _ := newServiceForTest(t, cfg, provider)
+
+	p1, err := svc.ProcessEvent(context.Background(), baseEvent("e1"))
+	if err != nil {
+		t.Fatalf("first event failed: %v", err)
+	}
+	if p1.Actions[0].Type != domain.ActionRequestGeneration {
+		t.Fatalf("expected first event within burst to proceed, got %+v", p1.Actions[0])
+	}
+
+	// Consume the room's Generating lock so the gate's lock check doesn't
+	// shadow the rate-limit check this test targets.
+	if _, err := svc.ProcessGeneration(context.Background(), domain.GenerationResult{
+		V: 0, PlanID: p1.PlanID, ActionID: p1.Actions[0].ActionID, TenantID: "t1", Text: "generated",
+	}); err != nil {
+		t.Fatalf("process generation failed: %v", err)
+	}
+
+	p2, err := svc.ProcessEvent(context.Background(), baseEvent("e2"))
+	if err != nil {
+		t.Fatalf("second event failed: %v", err)
+	}
+
+	action := p2.Actions[0]
+	if action.Type != domain.ActionDoNothing {
+		t.Fatalf("expected second event to exhaust the burst of 1 and be rate-limited, got %s", action.Type)
+	}
+	if got := action.Payload["reason_code"]; got != "rate_limited:tenant:room" {
+		t.Fatalf("reason_code mismatch: got %v", got)
+	}
+	if _, ok := action.Payload["retry_after_ms"]; !ok {
+		t.Fatalf("expected retry_after_ms in the denied action's payload, got %+v", action.Payload)
+	}
+}
@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChainedAppendLinksRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := NewJSONLLogger(path, WithChain(true))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := logger.Append(Record{AuditID: "a", TenantID: "t1", CorrelationID: "c", Action: "x", Result: "ok", ReasonCode: "r"}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+
+	if err := Verify(path); err != nil {
+		t.Fatalf("expected chain to verify, got %v", err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := NewJSONLLogger(path, WithChain(true))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := logger.Append(Record{AuditID: "a", TenantID: "t1", Action: "x", Result: "ok"}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	tampered := strings.Replace(string(b), `"result":"ok"`, `"result":"tampered"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatalf("write tampered file: %v", err)
+	}
+
+	if err := Verify(path); err == nil {
+		t.Fatalf("expected tampering to be detected")
+	}
+}
+
+func TestVerifyDetectsTipRecordTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := NewJSONLLogger(path, WithChain(true))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	if err := logger.Append(Record{AuditID: "a", TenantID: "t1", Action: "x", Result: "ok"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	tampered := strings.Replace(string(b), `"result":"ok"`, `"result":"tampered"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatalf("write tampered file: %v", err)
+	}
+
+	// A single-record file has no following record whose prev_hash could
+	// ever catch this: only the sidecar tip checkpoint can.
+	if err := Verify(path); err == nil {
+		t.Fatalf("expected tampering of the only (tip) record to be detected")
+	}
+}
+
+func TestVerifyDetectsTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := NewJSONLLogger(path, WithChain(true))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := logger.Append(Record{AuditID: "a", TenantID: "t1", Action: "x", Result: "ok"}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	truncated := strings.Join(lines[:len(lines)-1], "\n") + "\n"
+	if err := os.WriteFile(path, []byte(truncated), 0o644); err != nil {
+		t.Fatalf("write truncated file: %v", err)
+	}
+
+	if err := Verify(path); err == nil {
+		t.Fatalf("expected dropping the tip record to be detected as truncation")
+	}
+}
+
+func TestNewJSONLLoggerRefusesTamperedFileByDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := NewJSONLLogger(path, WithChain(true))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	_ = logger.Append(Record{AuditID: "a", TenantID: "t1", Action: "x", Result: "ok"})
+	_ = logger.Close()
+
+	b, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(b), `"result":"ok"`, `"result":"tampered"`, 1)
+	_ = os.WriteFile(path, []byte(tampered), 0o644)
+
+	if _, err := NewJSONLLogger(path, WithChain(true)); err == nil {
+		t.Fatalf("expected open to fail on a tampered chain with verify_on_open=true")
+	}
+
+	if _, err := NewJSONLLogger(path, WithChain(false)); err != nil {
+		t.Fatalf("expected open to succeed in warn mode, got %v", err)
+	}
+}
+
+func TestSignerWritesPeriodicCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := NewEd25519Signer(priv)
+
+	logger, err := NewJSONLLogger(path, WithChain(true), WithSigner(signer, 2, 0))
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := logger.Append(Record{AuditID: "a", TenantID: "t1", Action: "x", Result: "ok"}); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if !strings.Contains(string(b), `"action":"chain_checkpoint"`) {
+		t.Fatalf("expected a chain_checkpoint record after 2 appends, got:\n%s", b)
+	}
+}
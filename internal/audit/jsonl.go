@@ -1,8 +1,14 @@
 package audit
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"sync"
 	"time"
@@ -18,6 +24,22 @@ type Record struct {
 	TS            string `json:"ts"`
 
 	PlanID string `json:"plan_id,omitempty"`
+
+	// PolicyVersion traces which policy build produced Action's decision,
+	// e.g. an external planner's policy_version echoed back from its response.
+	PolicyVersion string `json:"policy_version,omitempty"`
+
+	// Chain fields. Populated by JSONLLogger when chaining is enabled; a
+	// record's Seq/PrevHash are set before it is marshaled, and its own hash
+	// is the SHA-256 of that marshaled line (never stored in the record
+	// itself, to avoid a circular self-reference).
+	Seq      uint64 `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+
+	// Signature is only set on periodic "chain_checkpoint" records: a
+	// base64-encoded signature (see Signer) over the tip hash at that point
+	// in the chain.
+	Signature string `json:"signature,omitempty"`
 }
 
 type Logger interface {
@@ -25,26 +47,98 @@ type Logger interface {
 	Close() error
 }
 
+// Option configures chaining/signing behavior for a JSONLLogger.
+type Option func(*JSONLLogger)
+
+// WithChain enables hash-chaining of appended records. When verifyOnOpen is
+// true, NewJSONLLogger walks the existing file end-to-end and refuses to
+// open if the chain doesn't verify; when false it logs a warning and
+// recovers chain state from the last record instead of failing.
+func WithChain(verifyOnOpen bool) Option {
+	return func(l *JSONLLogger) {
+		l.chainEnabled = true
+		l.verifyOnOpen = verifyOnOpen
+	}
+}
+
+// WithSigner arranges for a checkpoint record (Action: "chain_checkpoint")
+// to be appended, signing the current tip hash, whenever at least everyN
+// records have been appended since the last checkpoint or interval has
+// elapsed since the last checkpoint, whichever comes first. Both triggers are
+// checked only when a record is appended, so no checkpoint is written while
+// the log is idle. A zero everyN or interval disables that trigger.
+func WithSigner(signer Signer, everyN uint64, interval time.Duration) Option {
+	return func(l *JSONLLogger) {
+		l.signer = signer
+		l.signEveryN = everyN
+		l.signInterval = interval
+	}
+}
+
 type JSONLLogger struct {
-	mu sync.Mutex
-	f  *os.File
+	mu   sync.Mutex
+	f    *os.File
+	path string
+
+	chainEnabled bool
+	verifyOnOpen bool
+	seq          uint64
+	lastHash     string
+
+	signer           Signer
+	signEveryN       uint64
+	signInterval     time.Duration
+	recordsSinceSign uint64
+	lastSignAt       time.Time
 }
 
-func NewJSONLLogger(path string) (*JSONLLogger, error) {
+func NewJSONLLogger(path string, opts ...Option) (*JSONLLogger, error) {
+	l := &JSONLLogger{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.chainEnabled {
+		state, err := verifyChain(path)
+		if err != nil {
+			if l.verifyOnOpen {
+				return nil, fmt.Errorf("audit chain verification failed: %w", err)
+			}
+			log.Printf("audit: chain verification failed, opening in warn mode: %v", err)
+			state, err = recoverLastChainState(path)
+			if err != nil {
+				return nil, fmt.Errorf("recover audit chain state: %w", err)
+			}
+		}
+		l.seq = state.seq
+		l.lastHash = state.lastHash
+	}
+
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
 	if err != nil {
 		return nil, fmt.Errorf("open audit file: %w", err)
 	}
-	return &JSONLLogger{f: f}, nil
+	l.f = f
+	l.path = path
+	l.lastSignAt = time.Now()
+	return l, nil
 }
 
 func (l *JSONLLogger) Append(record Record) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	return l.appendLocked(record)
+}
 
+func (l *JSONLLogger) appendLocked(record Record) error {
 	if record.TS == "" {
 		record.TS = time.Now().UTC().Format(time.RFC3339Nano)
 	}
+	if l.chainEnabled {
+		record.Seq = l.seq + 1
+		record.PrevHash = l.lastHash
+	}
+
 	b, err := json.Marshal(record)
 	if err != nil {
 		return fmt.Errorf("marshal audit record: %w", err)
@@ -52,9 +146,44 @@ func (l *JSONLLogger) Append(record Record) error {
 	if _, err := l.f.Write(append(b, '\n')); err != nil {
 		return fmt.Errorf("write audit record: %w", err)
 	}
+
+	if l.chainEnabled {
+		l.seq = record.Seq
+		l.lastHash = computeHash(b)
+		if err := writeTip(l.path, chainState{seq: l.seq, lastHash: l.lastHash}); err != nil {
+			return fmt.Errorf("write chain tip checkpoint: %w", err)
+		}
+		l.recordsSinceSign++
+		l.maybeCheckpointLocked()
+	}
 	return nil
 }
 
+func (l *JSONLLogger) maybeCheckpointLocked() {
+	if l.signer == nil {
+		return
+	}
+	due := (l.signEveryN > 0 && l.recordsSinceSign >= l.signEveryN) ||
+		(l.signInterval > 0 && time.Since(l.lastSignAt) >= l.signInterval)
+	if !due {
+		return
+	}
+	l.recordsSinceSign = 0
+	l.lastSignAt = time.Now()
+
+	sig, err := l.signer.Sign([]byte(l.lastHash))
+	if err != nil {
+		log.Printf("audit: failed to sign chain checkpoint: %v", err)
+		return
+	}
+	_ = l.appendLocked(Record{
+		Action:     "chain_checkpoint",
+		Result:     "signed",
+		ReasonCode: "periodic",
+		Signature:  base64.StdEncoding.EncodeToString(sig),
+	})
+}
+
 func (l *JSONLLogger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -65,3 +194,171 @@ func (l *JSONLLogger) Close() error {
 	l.f = nil
 	return err
 }
+
+type chainState struct {
+	seq      uint64
+	lastHash string
+}
+
+func computeHash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// chainTip is the sidecar checkpoint written after every chained append,
+// recording the tip record's own seq and hash. Nothing in path's own
+// content can vouch for its last line -- a following record's prev_hash
+// only ever validates the record *before* it -- so verifyChain compares the
+// tip it recomputes by rescanning path against this external checkpoint to
+// catch the last record being edited or the file being truncated.
+type chainTip struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+func tipPath(path string) string {
+	return path + ".tip"
+}
+
+// writeTip persists state as path's chain tip checkpoint, writing to a temp
+// file and renaming over the checkpoint so a crash mid-write can never leave
+// a partially-written (and therefore unparseable, or worse, silently wrong)
+// checkpoint behind.
+func writeTip(path string, state chainState) error {
+	b, err := json.Marshal(chainTip{Seq: state.seq, Hash: state.lastHash})
+	if err != nil {
+		return fmt.Errorf("marshal chain tip: %w", err)
+	}
+	tmp := tipPath(path) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("write chain tip: %w", err)
+	}
+	if err := os.Rename(tmp, tipPath(path)); err != nil {
+		return fmt.Errorf("rename chain tip into place: %w", err)
+	}
+	return nil
+}
+
+// readTip reads path's chain tip checkpoint, if any.
+func readTip(path string) (chainTip, bool, error) {
+	b, err := os.ReadFile(tipPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return chainTip{}, false, nil
+		}
+		return chainTip{}, false, fmt.Errorf("read chain tip: %w", err)
+	}
+	var tip chainTip
+	if err := json.Unmarshal(b, &tip); err != nil {
+		return chainTip{}, false, fmt.Errorf("parse chain tip: %w", err)
+	}
+	return tip, true, nil
+}
+
+// verifyChain walks path end-to-end, validating that each record's seq and
+// prev_hash correctly link to the one before it, then checks the resulting
+// tip against path's sidecar checkpoint so tampering or truncation of the
+// last record -- which no following record's prev_hash can catch -- is
+// still detected. A missing file is treated as an empty, valid chain.
+func verifyChain(path string) (chainState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return chainState{}, nil
+		}
+		return chainState{}, fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	var state chainState
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return chainState{}, fmt.Errorf("line %d: invalid json: %w", lineNo, err)
+		}
+		if rec.Seq != state.seq+1 {
+			return chainState{}, fmt.Errorf("line %d: expected seq %d, got %d", lineNo, state.seq+1, rec.Seq)
+		}
+		if rec.PrevHash != state.lastHash {
+			return chainState{}, fmt.Errorf("line %d: prev_hash mismatch, chain is broken", lineNo)
+		}
+		state.seq = rec.Seq
+		state.lastHash = computeHash(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return chainState{}, fmt.Errorf("read audit file: %w", err)
+	}
+
+	tip, ok, err := readTip(path)
+	if err != nil {
+		return chainState{}, err
+	}
+	if !ok {
+		if state.seq > 0 {
+			return chainState{}, fmt.Errorf("missing chain tip checkpoint for a non-empty chain")
+		}
+		return state, nil
+	}
+	if tip.Seq != state.seq || tip.Hash != state.lastHash {
+		return chainState{}, fmt.Errorf("tip checkpoint mismatch (want seq %d hash %s, file ends at seq %d hash %s): file was truncated or its last record was edited", tip.Seq, tip.Hash, state.seq, state.lastHash)
+	}
+	return state, nil
+}
+
+// recoverLastChainState parses only the last record in path, without
+// validating the chain behind it, so a logger can keep appending (in warn
+// mode) after verification has already reported a break.
+func recoverLastChainState(path string) (chainState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return chainState{}, nil
+		}
+		return chainState{}, fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	var state chainState
+	var lastLine []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lastLine = append(lastLine[:0], line...)
+	}
+	if err := scanner.Err(); err != nil {
+		return chainState{}, fmt.Errorf("read audit file: %w", err)
+	}
+	if lastLine == nil {
+		return state, nil
+	}
+
+	var rec Record
+	if err := json.Unmarshal(lastLine, &rec); err != nil {
+		return chainState{}, fmt.Errorf("parse last record: %w", err)
+	}
+	state.seq = rec.Seq
+	state.lastHash = computeHash(lastLine)
+	return state, nil
+}
+
+// Verify walks the audit file at path end-to-end and returns a non-nil error
+// describing the first broken link, if any. It's used by the
+// `arbiterctl audit verify` subcommand.
+func Verify(path string) error {
+	_, err := verifyChain(path)
+	return err
+}
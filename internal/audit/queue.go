@@ -0,0 +1,305 @@
+package audit
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy controls what a queuedSink does when its buffer is full.
+type OverflowPolicy string
+
+const (
+	OverflowBlock      OverflowPolicy = "block"
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+)
+
+// QueueMetrics reports a sink's queue depth and drop count.
+type QueueMetrics struct {
+	Depth   int64 `json:"depth"`
+	Dropped int64 `json:"dropped"`
+	Retries int64 `json:"retries"`
+}
+
+// BatchAppender is implemented by sinks that can deliver several records in
+// a single round trip (e.g. an HTTP webhook posting a JSON array, or a Kafka
+// producer). A queuedSink delivers through AppendBatch instead of looping
+// Append whenever its inner sink implements it, whatever the batch size.
+type BatchAppender interface {
+	AppendBatch(records []Record) error
+}
+
+// RetryConfig bounds how many times a queuedSink retries a failed delivery,
+// with exponential backoff between attempts. The zero value disables
+// retrying: a failed delivery is dropped after a single attempt.
+type RetryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// queueOption configures optional batching/retry behavior on a queuedSink.
+// Callers that don't need it (most existing tests) can ignore it entirely.
+type queueOption func(*queuedSink)
+
+// WithBatch makes the queuedSink accumulate up to size records (or wait at
+// most flushInterval between flushes, whichever comes first) before
+// delivering them to the inner sink. size <= 0 behaves like a batch size of
+// 1; flushInterval <= 0 disables the time-based flush, so a partial batch
+// only flushes once it reaches size.
+func WithBatch(size int, flushInterval time.Duration) queueOption {
+	return func(q *queuedSink) {
+		if size > 0 {
+			q.batchSize = size
+		}
+		q.flushInterval = flushInterval
+	}
+}
+
+// WithRetry makes the queuedSink retry a failed delivery (single record or
+// batch) per cfg before counting it as dropped.
+func WithRetry(cfg RetryConfig) queueOption {
+	return func(q *queuedSink) {
+		q.retry = cfg
+	}
+}
+
+// queuedSink wraps a Logger with a bounded channel and a single worker
+// goroutine, so a slow or failing sink applies backpressure (or drops
+// records, per overflow) instead of being written inline. Under the block
+// policy a full queue makes Append wait for the worker, which holds up the
+// Multiplexer.Append call and the sinks after it. It optionally batches records and retries failed
+// deliveries with backoff before dropping them.
+type queuedSink struct {
+	name     string
+	inner    Logger
+	overflow OverflowPolicy
+
+	batchSize     int
+	flushInterval time.Duration
+	retry         RetryConfig
+
+	ch      chan Record
+	depth   int64
+	dropped int64
+	retries int64
+
+	wg sync.WaitGroup
+}
+
+func newQueuedSink(name string, inner Logger, size int, overflow OverflowPolicy, opts ...queueOption) *queuedSink {
+	if overflow == "" {
+		overflow = OverflowBlock
+	}
+	q := &queuedSink{
+		name:      name,
+		inner:     inner,
+		overflow:  overflow,
+		batchSize: 1,
+		ch:        make(chan Record, size),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+func (q *queuedSink) run() {
+	defer q.wg.Done()
+
+	batch := make([]Record, 0, q.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		q.deliver(batch)
+		batch = batch[:0]
+	}
+
+	var tick <-chan time.Time
+	if q.flushInterval > 0 {
+		ticker := time.NewTicker(q.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case rec, ok := <-q.ch:
+			if !ok {
+				flush()
+				return
+			}
+			atomic.AddInt64(&q.depth, -1)
+			batch = append(batch, rec)
+			if len(batch) >= q.batchSize {
+				flush()
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
+
+// deliver writes batch to the inner sink, retrying per q.retry, and counts
+// every record still unwritten after retries are exhausted as dropped.
+func (q *queuedSink) deliver(batch []Record) {
+	if ba, ok := q.inner.(BatchAppender); ok {
+		if err := q.withRetry(func() error { return ba.AppendBatch(batch) }); err != nil {
+			atomic.AddInt64(&q.dropped, int64(len(batch)))
+			log.Printf("audit: sink %s failed to append %d record(s) after retries: %v", q.name, len(batch), err)
+		}
+		return
+	}
+
+	for _, rec := range batch {
+		rec := rec
+		if err := q.withRetry(func() error { return q.inner.Append(rec) }); err != nil {
+			atomic.AddInt64(&q.dropped, 1)
+			log.Printf("audit: sink %s failed to append record: %v", q.name, err)
+		}
+	}
+}
+
+func (q *queuedSink) withRetry(fn func() error) error {
+	err := fn()
+	if err == nil || q.retry.MaxRetries <= 0 {
+		return err
+	}
+
+	backoff := q.retry.InitialBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	for attempt := 1; attempt <= q.retry.MaxRetries; attempt++ {
+		time.Sleep(backoff)
+		atomic.AddInt64(&q.retries, 1)
+		if err = fn(); err == nil {
+			return nil
+		}
+		backoff *= 2
+		if q.retry.MaxBackoff > 0 && backoff > q.retry.MaxBackoff {
+			backoff = q.retry.MaxBackoff
+		}
+	}
+	return err
+}
+
+// Append never blocks the caller beyond what the configured overflow policy
+// requires, and always returns nil: a dropped or slow-to-land record must
+// not fail the event pipeline that produced it.
+func (q *queuedSink) Append(record Record) error {
+	switch q.overflow {
+	case OverflowDropNewest:
+		select {
+		case q.ch <- record:
+			atomic.AddInt64(&q.depth, 1)
+		default:
+			atomic.AddInt64(&q.dropped, 1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- record:
+				atomic.AddInt64(&q.depth, 1)
+				return nil
+			default:
+			}
+			select {
+			case <-q.ch:
+				atomic.AddInt64(&q.depth, -1)
+				atomic.AddInt64(&q.dropped, 1)
+			default:
+			}
+		}
+	default: // block
+		q.ch <- record
+		atomic.AddInt64(&q.depth, 1)
+	}
+	return nil
+}
+
+func (q *queuedSink) Metrics() QueueMetrics {
+	return QueueMetrics{
+		Depth:   atomic.LoadInt64(&q.depth),
+		Dropped: atomic.LoadInt64(&q.dropped),
+		Retries: atomic.LoadInt64(&q.retries),
+	}
+}
+
+func (q *queuedSink) Close() error {
+	close(q.ch)
+	q.wg.Wait()
+	return q.inner.Close()
+}
+
+// durableSink is a named Logger written synchronously on Multiplexer.Append,
+// rather than through a queuedSink, so a failure to persist it surfaces to
+// the caller instead of being silently dropped after retries.
+type durableSink struct {
+	name  string
+	inner Logger
+}
+
+// Multiplexer fans a single Append out to every configured sink. Durable
+// sinks (the local jsonl/jsonl_rotating audit log chunk0-2's hash chain and
+// chunk0-3's gap-free sequencing depend on) are written synchronously and
+// their error is returned to the caller; every other sink goes through a
+// concurrently-safe queuedSink, so a slow or failing best-effort sink holds
+// up neither the others nor the caller until its queue is full; from then on
+// drop_oldest/drop_newest drop records, while block makes Append (and the
+// sinks after that one) wait for room in its queue. app.Service only ever talks to a
+// Multiplexer through the Logger interface, so adding or removing sinks
+// never touches a call site.
+type Multiplexer struct {
+	durable []durableSink
+	sinks   []*queuedSink
+}
+
+// Append writes to every durable sink first, synchronously, and returns the
+// first error encountered -- the caller (app.Service.RecordActionResult /
+// ProcessEvent) must see a durable-sink failure rather than reporting
+// success over a gap in the audit chain. Best-effort sinks are then handed
+// the record via their queue regardless of whether a durable sink failed.
+func (m *Multiplexer) Append(record Record) error {
+	var firstErr error
+	for _, d := range m.durable {
+		if err := d.inner.Append(record); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("audit sink %s: %w", d.name, err)
+		}
+	}
+	for _, s := range m.sinks {
+		_ = s.Append(record)
+	}
+	return firstErr
+}
+
+func (m *Multiplexer) Close() error {
+	var firstErr error
+	for _, d := range m.durable {
+		if err := d.inner.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Metrics returns per-sink queue depth/drop/retry counters, keyed by sink
+// type (e.g. for surfacing under /v0/healthz).
+func (m *Multiplexer) Metrics() map[string]QueueMetrics {
+	out := make(map[string]QueueMetrics, len(m.sinks))
+	for _, s := range m.sinks {
+		out[s.name] = s.Metrics()
+	}
+	return out
+}
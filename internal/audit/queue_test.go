@@ -0,0 +1,293 @@
+package audit
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingSink struct {
+	mu      sync.Mutex
+	release chan struct{}
+	got     []Record
+}
+
+func (b *blockingSink) Append(r Record) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.got = append(b.got, r)
+	return nil
+}
+
+func (b *blockingSink) Close() error { return nil }
+
+func TestQueuedSinkDropOldest(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	q := newQueuedSink("test", sink, 1, OverflowDropOldest)
+	defer func() {
+		close(sink.release)
+		q.Close()
+	}()
+
+	// the worker immediately blocks on the first record, so the channel
+	// buffer of size 1 fills with the second and the third evicts it.
+	_ = q.Append(Record{AuditID: "a1"})
+	time.Sleep(10 * time.Millisecond)
+	_ = q.Append(Record{AuditID: "a2"})
+	_ = q.Append(Record{AuditID: "a3"})
+
+	if got := q.Metrics().Dropped; got < 1 {
+		t.Fatalf("expected at least one drop, got %d", got)
+	}
+}
+
+func TestQueuedSinkDropNewest(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	q := newQueuedSink("test", sink, 1, OverflowDropNewest)
+	defer func() {
+		close(sink.release)
+		q.Close()
+	}()
+
+	_ = q.Append(Record{AuditID: "a1"})
+	time.Sleep(10 * time.Millisecond)
+	_ = q.Append(Record{AuditID: "a2"})
+	_ = q.Append(Record{AuditID: "a3"})
+
+	if got := q.Metrics().Dropped; got != 1 {
+		t.Fatalf("expected exactly one drop_newest drop, got %d", got)
+	}
+}
+
+type countingSink struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *countingSink) Append(Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	return nil
+}
+
+func (c *countingSink) Close() error { return nil }
+
+func TestMultiplexerFansOutToEverySink(t *testing.T) {
+	a := &countingSink{}
+	b := &countingSink{}
+	m := &Multiplexer{sinks: []*queuedSink{
+		newQueuedSink("a", a, 10, OverflowBlock),
+		newQueuedSink("b", b, 10, OverflowBlock),
+	}}
+
+	if err := m.Append(Record{AuditID: "x"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	a.mu.Lock()
+	b.mu.Lock()
+	defer a.mu.Unlock()
+	defer b.mu.Unlock()
+	if a.count != 1 || b.count != 1 {
+		t.Fatalf("expected both sinks to receive the record, got a=%d b=%d", a.count, b.count)
+	}
+}
+
+type alwaysFailingSink struct{}
+
+func (alwaysFailingSink) Append(Record) error { return errors.New("sink is down") }
+func (alwaysFailingSink) Close() error        { return nil }
+
+func TestMultiplexerFailingSinkDoesNotBlockOthers(t *testing.T) {
+	good := &countingSink{}
+
+	m := &Multiplexer{sinks: []*queuedSink{
+		newQueuedSink("fail", alwaysFailingSink{}, 10, OverflowBlock),
+		newQueuedSink("good", good, 10, OverflowBlock),
+	}}
+	defer m.Close()
+
+	for i := 0; i < 5; i++ {
+		_ = m.Append(Record{AuditID: "x"})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		good.mu.Lock()
+		n := good.count
+		good.mu.Unlock()
+		if n == 5 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected good sink to receive all records even if another sink were failing")
+}
+
+type batchSink struct {
+	mu      sync.Mutex
+	batches [][]Record
+}
+
+func (b *batchSink) Append(r Record) error { return b.AppendBatch([]Record{r}) }
+
+func (b *batchSink) AppendBatch(records []Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch := append([]Record(nil), records...)
+	b.batches = append(b.batches, batch)
+	return nil
+}
+
+func (b *batchSink) Close() error { return nil }
+
+func TestQueuedSinkBatchesByCount(t *testing.T) {
+	sink := &batchSink{}
+	q := newQueuedSink("test", sink, 10, OverflowBlock, WithBatch(2, 0))
+	defer q.Close()
+
+	_ = q.Append(Record{AuditID: "a1"})
+	_ = q.Append(Record{AuditID: "a2"})
+	_ = q.Append(Record{AuditID: "a3"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		sink.mu.Lock()
+		n := len(sink.batches)
+		sink.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.batches) != 1 || len(sink.batches[0]) != 2 {
+		t.Fatalf("expected one batch of 2 records delivered once the batch size was reached, got %+v", sink.batches)
+	}
+}
+
+func TestQueuedSinkFlushesPartialBatchOnInterval(t *testing.T) {
+	sink := &batchSink{}
+	q := newQueuedSink("test", sink, 10, OverflowBlock, WithBatch(10, 10*time.Millisecond))
+	defer q.Close()
+
+	_ = q.Append(Record{AuditID: "a1"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		sink.mu.Lock()
+		n := len(sink.batches)
+		sink.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected the flush interval to deliver the partial batch")
+}
+
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	got      []Record
+}
+
+func (f *flakySink) Append(r Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("not yet")
+	}
+	f.got = append(f.got, r)
+	return nil
+}
+
+func (f *flakySink) Close() error { return nil }
+
+func TestQueuedSinkRetriesBeforeDropping(t *testing.T) {
+	sink := &flakySink{failures: 2}
+	q := newQueuedSink("test", sink, 10, OverflowBlock, WithRetry(RetryConfig{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+	}))
+	defer q.Close()
+
+	_ = q.Append(Record{AuditID: "a1"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		sink.mu.Lock()
+		n := len(sink.got)
+		sink.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := q.Metrics(); got.Dropped != 0 || got.Retries != 2 {
+		t.Fatalf("expected the record to land after 2 retries with nothing dropped, got %+v", got)
+	}
+}
+
+func TestMultiplexerPropagatesDurableSinkError(t *testing.T) {
+	m := &Multiplexer{durable: []durableSink{{name: "jsonl", inner: alwaysFailingSink{}}}}
+
+	if err := m.Append(Record{AuditID: "x"}); err == nil {
+		t.Fatal("expected the durable sink's error to be returned, not swallowed")
+	}
+}
+
+func TestMultiplexerDurableSinkFailureDoesNotBlockBestEffortSinks(t *testing.T) {
+	good := &countingSink{}
+	m := &Multiplexer{
+		durable: []durableSink{{name: "jsonl", inner: alwaysFailingSink{}}},
+		sinks:   []*queuedSink{newQueuedSink("good", good, 10, OverflowBlock)},
+	}
+	defer m.Close()
+
+	if err := m.Append(Record{AuditID: "x"}); err == nil {
+		t.Fatal("expected an error from the durable sink")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		good.mu.Lock()
+		n := good.count
+		good.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected the best-effort sink to still receive the record")
+}
+
+func TestQueuedSinkDropsAfterRetriesExhausted(t *testing.T) {
+	sink := &flakySink{failures: 100}
+	q := newQueuedSink("test", sink, 10, OverflowBlock, WithRetry(RetryConfig{
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+	}))
+	defer q.Close()
+
+	_ = q.Append(Record{AuditID: "a1"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if q.Metrics().Dropped == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected the record to be dropped once retries were exhausted")
+}
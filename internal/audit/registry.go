@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// durableSinkTypes are journaled synchronously by Multiplexer.Append instead
+// of being queued and best-effort dropped on overflow/retry exhaustion --
+// they're the local, durable audit log (plain or rotating) that chunk0-2's
+// hash chain and chunk0-2/chunk0-3's gap-free sequencing rely on.
+var durableSinkTypes = map[string]bool{
+	"jsonl":          true,
+	"jsonl_rotating": true,
+}
+
+// NewMultiplexerFromConfig builds every sink in cfgs, wraps each
+// non-durable sink in its own bounded queue, and returns a single Logger
+// that fans records out to all of them. A failure building any sink
+// prevents startup; once running, a failure writing to a best-effort sink
+// never prevents the others from receiving records (though under the block
+// overflow policy a full queue delays them), but a failure writing to a
+// durable sink is returned to the caller instead of being swallowed.
+func NewMultiplexerFromConfig(cfgs []config.AuditSinkConfig) (*Multiplexer, error) {
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("at least one audit sink is required")
+	}
+
+	var durable []durableSink
+	var sinks []*queuedSink
+	for _, c := range cfgs {
+		inner, err := newSink(c)
+		if err != nil {
+			return nil, fmt.Errorf("audit sink %q: %w", c.Type, err)
+		}
+
+		if durableSinkTypes[c.Type] {
+			durable = append(durable, durableSink{name: c.Type, inner: inner})
+			continue
+		}
+
+		size := c.Queue.Size
+		if size <= 0 {
+			size = 1000
+		}
+		opts := []queueOption{
+			WithBatch(c.Queue.BatchSize, time.Duration(c.Queue.FlushIntervalMS)*time.Millisecond),
+			WithRetry(RetryConfig{
+				MaxRetries:     c.Queue.Retry.MaxRetries,
+				InitialBackoff: time.Duration(c.Queue.Retry.InitialBackoffMS) * time.Millisecond,
+				MaxBackoff:     time.Duration(c.Queue.Retry.MaxBackoffMS) * time.Millisecond,
+			}),
+		}
+		sinks = append(sinks, newQueuedSink(c.Type, inner, size, OverflowPolicy(c.Queue.Overflow), opts...))
+	}
+	return &Multiplexer{durable: durable, sinks: sinks}, nil
+}
+
+func newSink(c config.AuditSinkConfig) (Logger, error) {
+	switch c.Type {
+	case "jsonl":
+		return newJSONLSinkFromConfig(c.JSONL)
+	case "jsonl_rotating":
+		return NewRotatingJSONLLogger(c.JSONLRotating)
+	case "syslog":
+		return NewSyslogLogger(c.Syslog)
+	case "otlp_logs":
+		return NewOTLPLogsLogger(c.OTLPLogs)
+	case "kafka":
+		return NewKafkaLogger(c.Kafka)
+	case "webhook":
+		return NewWebhookLogger(c.Webhook)
+	case "stdout":
+		return NewStdoutLogger(c.Stdout)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", c.Type)
+	}
+}
+
+func newJSONLSinkFromConfig(c config.AuditJSONLSinkConfig) (Logger, error) {
+	var opts []Option
+	if c.Chain.Enabled {
+		opts = append(opts, WithChain(c.Chain.VerifyOnOpen))
+	}
+	if c.Signing.Enabled {
+		signer, err := LoadEd25519SignerFile(c.Signing.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("load signing key: %w", err)
+		}
+		opts = append(opts, WithSigner(signer, 0, time.Duration(c.Signing.IntervalMS)*time.Millisecond))
+	}
+	return NewJSONLLogger(c.Path, opts...)
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+)
+
+// Signer produces a signature over a chain tip hash. It lets a JSONLLogger
+// write periodic checkpoints that can be verified offline without requiring
+// an external transparency service.
+type Signer interface {
+	Sign(tipHash []byte) ([]byte, error)
+}
+
+// Ed25519Signer signs chain tips with a raw Ed25519 private key.
+type Ed25519Signer struct {
+	priv ed25519.PrivateKey
+}
+
+func NewEd25519Signer(priv ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{priv: priv}
+}
+
+// LoadEd25519SignerFile reads a raw (non-PEM) ed25519.PrivateKeySize-byte key
+// from keyPath.
+func LoadEd25519SignerFile(keyPath string) (*Ed25519Signer, error) {
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	if len(b) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key at %s must be %d bytes, got %d", keyPath, ed25519.PrivateKeySize, len(b))
+	}
+	return &Ed25519Signer{priv: ed25519.PrivateKey(b)}, nil
+}
+
+func (s *Ed25519Signer) Sign(tipHash []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, tipHash), nil
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// KafkaLogger publishes audit records to a Kafka topic asynchronously, keyed
+// by tenant ID so a tenant's records always land on the same partition and
+// keep their order.
+type KafkaLogger struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaLogger(cfg config.AuditKafkaSinkConfig) (*KafkaLogger, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka.brokers is required")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka.topic is required")
+	}
+
+	return &KafkaLogger{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{},
+			Async:        true,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}, nil
+}
+
+func (l *KafkaLogger) Append(record Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	return l.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(record.TenantID),
+		Value: b,
+	})
+}
+
+func (l *KafkaLogger) Close() error {
+	return l.writer.Close()
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// OTLPLogsLogger exports audit records as OTLP LogRecords over the OTLP/HTTP
+// JSON transport (the Collector's /v1/logs receiver). arbiter has no
+// protobuf/gRPC toolchain yet, so this speaks the JSON encoding of the same
+// export.logs.v1.ExportLogsServiceRequest schema rather than gRPC+protobuf.
+type OTLPLogsLogger struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+}
+
+func NewOTLPLogsLogger(cfg config.AuditOTLPSinkConfig) (*OTLPLogsLogger, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("otlp_logs.endpoint is required")
+	}
+	timeout := time.Duration(cfg.TimeoutMS) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "arbiter"
+	}
+
+	return &OTLPLogsLogger{
+		endpoint:    cfg.Endpoint,
+		serviceName: serviceName,
+		headers:     cfg.Headers,
+		client:      &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (l *OTLPLogsLogger) Append(record Record) error {
+	body, err := json.Marshal(l.exportRequest(record))
+	if err != nil {
+		return fmt.Errorf("marshal otlp export request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build otlp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range l.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send otlp export: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp export rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// exportRequest builds the minimal ExportLogsServiceRequest arbiter needs:
+// one resource log tagged with service.name, one log record per audit event
+// carrying tenant.id and correlation.id attributes.
+func (l *OTLPLogsLogger) exportRequest(record Record) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceLogs": []map[string]interface{}{
+			{
+				"resource": map[string]interface{}{
+					"attributes": []map[string]interface{}{
+						{"key": "service.name", "value": map[string]interface{}{"stringValue": l.serviceName}},
+					},
+				},
+				"scopeLogs": []map[string]interface{}{
+					{
+						"logRecords": []map[string]interface{}{
+							{
+								"timeUnixNano": fmt.Sprintf("%d", time.Now().UnixNano()),
+								"severityText": "INFO",
+								"body":         map[string]interface{}{"stringValue": record.Action + ":" + record.Result},
+								"attributes": []map[string]interface{}{
+									{"key": "tenant.id", "value": map[string]interface{}{"stringValue": record.TenantID}},
+									{"key": "correlation.id", "value": map[string]interface{}{"stringValue": record.CorrelationID}},
+									{"key": "reason_code", "value": map[string]interface{}{"stringValue": record.ReasonCode}},
+									{"key": "plan_id", "value": map[string]interface{}{"stringValue": record.PlanID}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (l *OTLPLogsLogger) Close() error {
+	return nil
+}
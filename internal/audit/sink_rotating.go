@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// RotatingJSONLLogger is a jsonl sink that rotates to a new file once the
+// current one exceeds MaxSizeMB or MaxAgeHours, optionally gzip-compressing
+// the rotated-out file in the background.
+type RotatingJSONLLogger struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	gzipOld  bool
+	f        *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func NewRotatingJSONLLogger(cfg config.AuditJSONLRotatingSinkConfig) (*RotatingJSONLLogger, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("jsonl_rotating.path is required")
+	}
+	l := &RotatingJSONLLogger{
+		path:    cfg.Path,
+		maxSize: int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxAge:  time.Duration(cfg.MaxAgeHours) * time.Hour,
+		gzipOld: cfg.Gzip,
+	}
+	if err := l.openCurrent(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RotatingJSONLLogger) openCurrent() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open rotating audit file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat rotating audit file: %w", err)
+	}
+	l.f = f
+	l.size = info.Size()
+	l.openedAt = time.Now()
+	return nil
+}
+
+func (l *RotatingJSONLLogger) Append(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if record.TS == "" {
+		record.TS = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	b = append(b, '\n')
+
+	if err := l.rotateIfNeededLocked(); err != nil {
+		return err
+	}
+
+	n, err := l.f.Write(b)
+	if err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+	l.size += int64(n)
+	return nil
+}
+
+func (l *RotatingJSONLLogger) rotateIfNeededLocked() error {
+	due := (l.maxSize > 0 && l.size >= l.maxSize) || (l.maxAge > 0 && time.Since(l.openedAt) >= l.maxAge)
+	if !due {
+		return nil
+	}
+
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("close rotating audit file: %w", err)
+	}
+
+	rotatedPath := fmt.Sprintf("%s.%s", l.path, time.Now().UTC().Format("20060102T150405.000000000Z"))
+	if err := os.Rename(l.path, rotatedPath); err != nil {
+		return fmt.Errorf("rotate audit file: %w", err)
+	}
+	if l.gzipOld {
+		go gzipAndRemove(rotatedPath)
+	}
+	return l.openCurrent()
+}
+
+func gzipAndRemove(path string) {
+	in, err := os.Open(path)
+	if err != nil {
+		log.Printf("audit: rotate gzip open failed for %s: %v", path, err)
+		return
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		log.Printf("audit: rotate gzip create failed for %s: %v", path, err)
+		return
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		log.Printf("audit: rotate gzip copy failed for %s: %v", path, err)
+		_ = gz.Close()
+		_ = out.Close()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		log.Printf("audit: rotate gzip close failed for %s: %v", path, err)
+		_ = out.Close()
+		return
+	}
+	if err := out.Close(); err != nil {
+		log.Printf("audit: rotate gzip close failed for %s: %v", path, err)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		log.Printf("audit: rotate cleanup failed for %s: %v", path, err)
+	}
+}
+
+func (l *RotatingJSONLLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// StdoutLogger writes each audit record as one JSON line to os.Stdout.
+// Pretty printing is meant for local development only; it's not newline
+// delimited and shouldn't be parsed by log shippers.
+type StdoutLogger struct {
+	mu     sync.Mutex
+	pretty bool
+}
+
+func NewStdoutLogger(cfg config.AuditStdoutSinkConfig) (*StdoutLogger, error) {
+	return &StdoutLogger{pretty: cfg.Pretty}, nil
+}
+
+func (l *StdoutLogger) Append(record Record) error {
+	var b []byte
+	var err error
+	if l.pretty {
+		b, err = json.MarshalIndent(record, "", "  ")
+	} else {
+		b, err = json.Marshal(record)
+	}
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = fmt.Fprintln(os.Stdout, string(b))
+	return err
+}
+
+func (l *StdoutLogger) Close() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// SyslogLogger writes audit records as RFC 5424 syslog messages, with the
+// canonical JSON record as the structured MSG part.
+type SyslogLogger struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	appName  string
+	facility int
+	hostname string
+}
+
+func NewSyslogLogger(cfg config.AuditSyslogSinkConfig) (*SyslogLogger, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog.address is required")
+	}
+
+	var conn net.Conn
+	var err error
+	switch cfg.Network {
+	case "udp", "tcp":
+		conn, err = net.Dial(cfg.Network, cfg.Address)
+	case "tls":
+		conn, err = tls.Dial("tcp", cfg.Address, &tls.Config{MinVersion: tls.VersionTLS12})
+	default:
+		return nil, fmt.Errorf("syslog.network must be udp, tcp, or tls, got %q", cfg.Network)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial syslog: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	appName := cfg.AppName
+	if appName == "" {
+		appName = "arbiter"
+	}
+	facility := cfg.Facility
+	if facility == 0 {
+		facility = 16 // local0
+	}
+
+	return &SyslogLogger{conn: conn, appName: appName, facility: facility, hostname: hostname}, nil
+}
+
+func (l *SyslogLogger) Append(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+
+	const severityInfo = 6
+	priority := l.facility*8 + severityInfo
+	msgID := record.AuditID
+	if msgID == "" {
+		msgID = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s\n",
+		priority, time.Now().UTC().Format(time.RFC3339Nano), l.hostname, l.appName, os.Getpid(), msgID, b)
+
+	if _, err := l.conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("write syslog message: %w", err)
+	}
+	return nil
+}
+
+func (l *SyslogLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.conn.Close()
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+)
+
+// WebhookLogger POSTs audit records as JSON to an HTTP endpoint, one record
+// per request by default. It also implements BatchAppender so a queuedSink
+// configured with a batch size > 1 can deliver several records as a single
+// JSON array in one round trip.
+type WebhookLogger struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func NewWebhookLogger(cfg config.AuditWebhookSinkConfig) (*WebhookLogger, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook.url is required")
+	}
+	timeout := time.Duration(cfg.TimeoutMS) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &WebhookLogger{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (l *WebhookLogger) Append(record Record) error {
+	return l.AppendBatch([]Record{record})
+}
+
+func (l *WebhookLogger) AppendBatch(records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshal audit records: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range l.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (l *WebhookLogger) Close() error {
+	return nil
+}
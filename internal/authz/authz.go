@@ -17,6 +17,14 @@ type Decision struct {
 	ReasonCode  string
 	PolicyVer   string
 	DecisionSrc string
+
+	// TTLMS is an optional per-decision cache TTL hint from the provider (0
+	// means "no opinion"); authz.Cached uses whichever of this and the
+	// configured ttl_ms is smaller.
+	TTLMS int
+	// NoStore tells authz.Cached never to memoize this decision, e.g. a
+	// transient failure-mode response that shouldn't mask recovery.
+	NoStore bool
 }
 
 type Provider interface {
@@ -56,20 +64,27 @@ type responsePayload struct {
 	ReasonCode    string `json:"reason_code"`
 	PolicyVersion string `json:"policy_version"`
 	TTLMS         int    `json:"ttl_ms"`
+	NoStore       bool   `json:"no_store"`
 }
 
 func NewProvider(cfg config.AuthzConfig) Provider {
 	builtin := BuiltinAllowAll{}
-	if cfg.Mode == "builtin" {
-		return builtin
+
+	var p Provider = builtin
+	if cfg.Mode != "builtin" {
+		p = &ExternalHTTP{
+			endpoint: cfg.Endpoint,
+			timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+			failMode: cfg.FailMode,
+			fallback: builtin,
+			client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMS) * time.Millisecond},
+		}
 	}
-	return &ExternalHTTP{
-		endpoint: cfg.Endpoint,
-		timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
-		failMode: cfg.FailMode,
-		fallback: builtin,
-		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMS) * time.Millisecond},
+
+	if cfg.Cache.Enabled {
+		p = Cached(p, cfg.Cache)
 	}
+	return p
 }
 
 func (e *ExternalHTTP) Authorize(ctx context.Context, ev domain.Event) Decision {
@@ -133,21 +148,27 @@ func (e *ExternalHTTP) Authorize(ctx context.Context, ev domain.Event) Decision
 		ReasonCode:  reason,
 		PolicyVer:   out.PolicyVersion,
 		DecisionSrc: "external_http",
+		TTLMS:       out.TTLMS,
+		NoStore:     out.NoStore,
 	}
 }
 
+// applyFailureMode decisions are never cached: they describe a transient
+// problem reaching the external provider, and memoizing one would keep
+// denying (or allowing) traffic long after the provider recovered.
 func (e *ExternalHTTP) applyFailureMode(ctx context.Context) Decision {
 	switch e.failMode {
 	case "allow":
-		return Decision{Allow: true, ReasonCode: "authz_error_allow", PolicyVer: "external-error", DecisionSrc: "external_http"}
+		return Decision{Allow: true, ReasonCode: "authz_error_allow", PolicyVer: "external-error", DecisionSrc: "external_http", NoStore: true}
 	case "fallback_builtin":
 		d := e.fallback.Authorize(ctx, domain.Event{})
 		d.ReasonCode = "authz_error_fallback_builtin"
+		d.NoStore = true
 		return d
 	case "deny":
 		fallthrough
 	default:
-		return Decision{Allow: false, ReasonCode: "authz_error_deny", PolicyVer: "external-error", DecisionSrc: "external_http"}
+		return Decision{Allow: false, ReasonCode: "authz_error_deny", PolicyVer: "external-error", DecisionSrc: "external_http", NoStore: true}
 	}
 }
 
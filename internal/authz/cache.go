@@ -0,0 +1,177 @@
+package authz
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// CacheMetrics reports a Cached provider's counters: authz_cache_hits_total,
+// authz_cache_misses_total, authz_cache_evictions_total, and
+// authz_cache_inflight (the number of upstream calls currently in flight).
+type CacheMetrics struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Inflight  int64
+}
+
+type cacheEntry struct {
+	key       string
+	decision  Decision
+	expiresAt time.Time
+}
+
+// cachedProvider decorates a Provider with a size-bounded, TTL-expiring LRU
+// keyed on the parts of an event that actually influence the decision, never
+// the raw text or timestamp. A singleflight.Group collapses a thundering
+// herd of identical cache misses into a single upstream call.
+type cachedProvider struct {
+	inner      Provider
+	ttl        time.Duration
+	maxEntries int
+
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	metrics CacheMetrics
+}
+
+// Cached wraps inner with a bounded LRU cache configured by cfg. NewProvider
+// only calls this when cfg.Enabled is true.
+func Cached(inner Provider, cfg config.AuthzCacheConfig) Provider {
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 1
+	}
+	return &cachedProvider{
+		inner:      inner,
+		ttl:        time.Duration(cfg.TTLMS) * time.Millisecond,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (c *cachedProvider) Authorize(ctx context.Context, ev domain.Event) Decision {
+	key := cacheKey(ev)
+
+	if d, ok := c.get(key); ok {
+		atomic.AddInt64(&c.metrics.Hits, 1)
+		return d
+	}
+	atomic.AddInt64(&c.metrics.Misses, 1)
+
+	v, _, _ := c.group.Do(key, func() (interface{}, error) {
+		atomic.AddInt64(&c.metrics.Inflight, 1)
+		defer atomic.AddInt64(&c.metrics.Inflight, -1)
+
+		d := c.inner.Authorize(ctx, ev)
+		c.put(key, d)
+		return d, nil
+	})
+	return v.(Decision)
+}
+
+// Metrics returns a snapshot of the cache's hit/miss/eviction/inflight
+// counters.
+func (c *cachedProvider) Metrics() CacheMetrics {
+	return CacheMetrics{
+		Hits:      atomic.LoadInt64(&c.metrics.Hits),
+		Misses:    atomic.LoadInt64(&c.metrics.Misses),
+		Evictions: atomic.LoadInt64(&c.metrics.Evictions),
+		Inflight:  atomic.LoadInt64(&c.metrics.Inflight),
+	}
+}
+
+func (c *cachedProvider) get(key string) (Decision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return Decision{}, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeLocked(el)
+		return Decision{}, false
+	}
+	c.lru.MoveToFront(el)
+	return entry.decision, true
+}
+
+func (c *cachedProvider) put(key string, d Decision) {
+	if d.NoStore {
+		return
+	}
+
+	ttl := c.ttl
+	if d.TTLMS > 0 {
+		if candidate := time.Duration(d.TTLMS) * time.Millisecond; ttl <= 0 || candidate < ttl {
+			ttl = candidate
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.decision = d
+		entry.expiresAt = time.Now().Add(ttl)
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	el := c.lru.PushFront(&cacheEntry{key: key, decision: d, expiresAt: time.Now().Add(ttl)})
+	c.entries[key] = el
+
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeLocked(oldest)
+		atomic.AddInt64(&c.metrics.Evictions, 1)
+	}
+}
+
+func (c *cachedProvider) removeLocked(el *list.Element) {
+	entry := el.Value.(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.lru.Remove(el)
+}
+
+// cacheKey hashes (tenant_id, actor.type, actor.id, sorted actor.roles,
+// room_id, content.type) -- deliberately never the raw event text or
+// timestamp, so two events that differ only in those fields share a cache
+// entry.
+func cacheKey(ev domain.Event) string {
+	roles := append([]string(nil), ev.Actor.Roles...)
+	sort.Strings(roles)
+
+	h := sha256.New()
+	for _, part := range []string{ev.TenantID, ev.Actor.Type, ev.Actor.ID, strings.Join(roles, ","), ev.RoomID, ev.Content.Type} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,140 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+func sampleEvent(tenantID string) domain.Event {
+	return domain.Event{
+		TenantID: tenantID,
+		EventID:  "e1",
+		RoomID:   "r1",
+		Actor:    domain.Actor{Type: "human", ID: "u1", Roles: []string{"member"}},
+		Content:  domain.EventContent{Type: "text", Text: "hello"},
+		TS:       time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// TestCachedSingleflightCollapsesThunderingHerd simulates a stalled
+// external_http endpoint (a handler that blocks until released) and checks
+// that 100 goroutines evaluating the same event concurrently produce
+// exactly one upstream request.
+func TestCachedSingleflightCollapsesThunderingHerd(t *testing.T) {
+	var requests int64
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"v":0,"decision":"allow","reason_code":"ok","ttl_ms":60000}`))
+	}))
+	defer srv.Close()
+
+	inner := &ExternalHTTP{endpoint: srv.URL, timeout: 5 * time.Second, failMode: "deny", fallback: BuiltinAllowAll{}, client: &http.Client{Timeout: 5 * time.Second}}
+	p := Cached(inner, config.AuthzCacheConfig{Enabled: true, TTLMS: 60000, MaxEntries: 1000})
+
+	ev := sampleEvent("t1")
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Authorize(context.Background(), ev)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond) // let every goroutine reach the singleflight call
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&requests); got != 1 {
+		t.Fatalf("expected exactly one upstream request, got %d", got)
+	}
+
+	// The leader's result populated the cache while the other 99 callers
+	// were parked in singleflight, so the next call is a genuine cache hit
+	// rather than a second upstream round trip.
+	p.Authorize(context.Background(), ev)
+	if got := atomic.LoadInt64(&requests); got != 1 {
+		t.Fatalf("expected the follow-up call to hit the cache, got %d upstream requests", got)
+	}
+	if hits := p.(*cachedProvider).Metrics().Hits; hits < 1 {
+		t.Fatalf("expected at least one recorded cache hit, got %d", hits)
+	}
+}
+
+func TestCachedKeyIgnoresTextAndTimestamp(t *testing.T) {
+	var calls int64
+	inner := providerFunc(func(context.Context, domain.Event) Decision {
+		atomic.AddInt64(&calls, 1)
+		return Decision{Allow: true, ReasonCode: "ok"}
+	})
+	p := Cached(inner, config.AuthzCacheConfig{Enabled: true, TTLMS: 60000, MaxEntries: 10})
+
+	a := sampleEvent("t1")
+	b := sampleEvent("t1")
+	b.Content.Text = "totally different text"
+	b.TS = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	p.Authorize(context.Background(), a)
+	p.Authorize(context.Background(), b)
+
+	if calls != 1 {
+		t.Fatalf("expected the second call to hit the cache despite differing text/ts, got %d upstream calls", calls)
+	}
+}
+
+func TestCachedHonorsNoStore(t *testing.T) {
+	var calls int64
+	inner := providerFunc(func(context.Context, domain.Event) Decision {
+		atomic.AddInt64(&calls, 1)
+		return Decision{Allow: false, ReasonCode: "denied", NoStore: true}
+	})
+	p := Cached(inner, config.AuthzCacheConfig{Enabled: true, TTLMS: 60000, MaxEntries: 10})
+
+	ev := sampleEvent("t1")
+	p.Authorize(context.Background(), ev)
+	p.Authorize(context.Background(), ev)
+
+	if calls != 2 {
+		t.Fatalf("expected NoStore decisions to bypass the cache every time, got %d calls", calls)
+	}
+}
+
+func TestCachedEvictsOldestWhenMaxEntriesExceeded(t *testing.T) {
+	var calls int64
+	inner := providerFunc(func(_ context.Context, ev domain.Event) Decision {
+		atomic.AddInt64(&calls, 1)
+		return Decision{Allow: true, ReasonCode: ev.TenantID}
+	})
+	p := Cached(inner, config.AuthzCacheConfig{Enabled: true, TTLMS: 60000, MaxEntries: 2}).(*cachedProvider)
+
+	p.Authorize(context.Background(), sampleEvent("t1"))
+	p.Authorize(context.Background(), sampleEvent("t2"))
+	p.Authorize(context.Background(), sampleEvent("t3")) // evicts t1
+
+	p.Authorize(context.Background(), sampleEvent("t1")) // misses, evicts t2
+
+	if calls != 4 {
+		t.Fatalf("expected t1 to miss again after eviction, got %d upstream calls", calls)
+	}
+	if p.Metrics().Evictions != 2 {
+		t.Fatalf("expected two evictions (t1 on the t3 insert, t2 on the re-inserted t1), got %d", p.Metrics().Evictions)
+	}
+}
+
+type providerFunc func(context.Context, domain.Event) Decision
+
+func (f providerFunc) Authorize(ctx context.Context, ev domain.Event) Decision {
+	return f(ctx, ev)
+}
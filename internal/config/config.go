@@ -18,12 +18,31 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	ListenAddr string `yaml:"listen_addr"`
+	ListenAddr           string `yaml:"listen_addr"`
+	GRPCListenAddr       string `yaml:"grpc_listen_addr"`
+	GRPCRequestTimeoutMS int    `yaml:"grpc_request_timeout_ms"`
 }
 
 type StoreConfig struct {
-	Type       string `yaml:"type"`
-	SQLitePath string `yaml:"sqlite_path"`
+	Type                  string         `yaml:"type"`
+	SQLitePath            string         `yaml:"sqlite_path"`
+	IdempotencyTTLSeconds int            `yaml:"idempotency_ttl_seconds"`
+	Redis                 RedisConfig    `yaml:"redis"`
+	Postgres              PostgresConfig `yaml:"postgres"`
+}
+
+type RedisConfig struct {
+	Addrs    []string `yaml:"addrs"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	DB       int      `yaml:"db"`
+	TLS      bool     `yaml:"tls"`
+	LeaseMS  int      `yaml:"lease_ms"`
+}
+
+type PostgresConfig struct {
+	DSN         string `yaml:"dsn"`
+	MaxOpenConn int    `yaml:"max_open_conns"`
 }
 
 type AuthzCacheConfig struct {
@@ -41,26 +60,141 @@ type AuthzConfig struct {
 }
 
 type GateConfig struct {
-	CooldownMS            int `yaml:"cooldown_ms"`
-	MaxQueue              int `yaml:"max_queue"`
-	TenantRateLimitPerMin int `yaml:"tenant_rate_limit_per_min"`
+	CooldownMS            int             `yaml:"cooldown_ms"`
+	MaxQueue              int             `yaml:"max_queue"`
+	TenantRateLimitPerMin int             `yaml:"tenant_rate_limit_per_min"`
+	RateLimit             RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitScopeConfig configures one internal/ratelimit bucket. Scope
+// selects which IDs from the event key the bucket -- "tenant" keys on
+// tenant_id alone, "tenant:room" also includes room_id, "tenant:actor"
+// also includes actor.id; a scope is skipped for an event missing the ID
+// it needs (e.g. "tenant:actor" on a system event with no actor). Kind
+// picks which store primitive backs it: token_bucket smooths bursts with a
+// continuously-refilling allowance, sliding_window caps exact event counts
+// over a trailing duration.
+type RateLimitScopeConfig struct {
+	Name       string  `yaml:"name"`
+	Scope      string  `yaml:"scope"`
+	Kind       string  `yaml:"kind"`
+	RatePerSec float64 `yaml:"rate_per_sec"`
+	Burst      int     `yaml:"burst"`
+	WindowMS   int     `yaml:"window_ms"`
+	Limit      int     `yaml:"limit"`
+}
+
+type RateLimitConfig struct {
+	Scopes []RateLimitScopeConfig `yaml:"scopes"`
 }
 
 type PlannerConfig struct {
 	ReplyPolicy      string  `yaml:"reply_policy"`
 	ReplyProbability float64 `yaml:"reply_probability"`
+
+	// Mode selects builtin (the ReplyPolicy ladder above) or external_http
+	// (POSTs the event to Endpoint and interprets its intent/reason_code/
+	// policy_version response), mirroring AuthzConfig's shape.
+	Mode      string `yaml:"mode"`
+	Endpoint  string `yaml:"endpoint"`
+	TimeoutMS int    `yaml:"timeout_ms"`
+	FailMode  string `yaml:"fail_mode"`
+}
+
+type AuditChainConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	VerifyOnOpen bool `yaml:"verify_on_open"`
+}
+
+type AuditSigningConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	KeyPath    string `yaml:"key_path"`
+	IntervalMS int    `yaml:"interval_ms"`
+}
+
+type AuditQueueConfig struct {
+	Size            int              `yaml:"size"`
+	Overflow        string           `yaml:"overflow"` // block, drop_oldest, drop_newest
+	BatchSize       int              `yaml:"batch_size"`
+	FlushIntervalMS int              `yaml:"flush_interval_ms"`
+	Retry           AuditRetryConfig `yaml:"retry"`
+}
+
+// AuditRetryConfig bounds per-sink delivery retries with exponential
+// backoff. MaxRetries <= 0 disables retrying: a failed batch is dropped
+// (and counted) after a single attempt.
+type AuditRetryConfig struct {
+	MaxRetries       int `yaml:"max_retries"`
+	InitialBackoffMS int `yaml:"initial_backoff_ms"`
+	MaxBackoffMS     int `yaml:"max_backoff_ms"`
+}
+
+type AuditJSONLSinkConfig struct {
+	Path    string             `yaml:"path"`
+	Chain   AuditChainConfig   `yaml:"chain"`
+	Signing AuditSigningConfig `yaml:"signing"`
+}
+
+type AuditJSONLRotatingSinkConfig struct {
+	Path        string `yaml:"path"`
+	MaxSizeMB   int    `yaml:"max_size_mb"`
+	MaxAgeHours int    `yaml:"max_age_hours"`
+	Gzip        bool   `yaml:"gzip"`
+}
+
+type AuditSyslogSinkConfig struct {
+	Network  string `yaml:"network"` // udp, tcp, tls
+	Address  string `yaml:"address"`
+	AppName  string `yaml:"app_name"`
+	Facility int    `yaml:"facility"`
+}
+
+type AuditOTLPSinkConfig struct {
+	Endpoint    string            `yaml:"endpoint"`
+	ServiceName string            `yaml:"service_name"`
+	Headers     map[string]string `yaml:"headers"`
+	TimeoutMS   int               `yaml:"timeout_ms"`
+}
+
+type AuditKafkaSinkConfig struct {
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
+}
+
+type AuditWebhookSinkConfig struct {
+	URL       string            `yaml:"url"`
+	Headers   map[string]string `yaml:"headers"`
+	TimeoutMS int               `yaml:"timeout_ms"`
+}
+
+type AuditStdoutSinkConfig struct {
+	Pretty bool `yaml:"pretty"`
+}
+
+// AuditSinkConfig configures one fan-out destination for audit records. Only
+// the section matching Type is read; the rest are ignored.
+type AuditSinkConfig struct {
+	Type  string           `yaml:"type"`
+	Queue AuditQueueConfig `yaml:"queue"`
+
+	JSONL         AuditJSONLSinkConfig         `yaml:"jsonl"`
+	JSONLRotating AuditJSONLRotatingSinkConfig `yaml:"jsonl_rotating"`
+	Syslog        AuditSyslogSinkConfig        `yaml:"syslog"`
+	OTLPLogs      AuditOTLPSinkConfig          `yaml:"otlp_logs"`
+	Kafka         AuditKafkaSinkConfig         `yaml:"kafka"`
+	Webhook       AuditWebhookSinkConfig       `yaml:"webhook"`
+	Stdout        AuditStdoutSinkConfig        `yaml:"stdout"`
 }
 
 type AuditConfig struct {
-	Sink                 string `yaml:"sink"`
-	JSONLPath            string `yaml:"jsonl_path"`
-	IncludeAuthzDecision bool   `yaml:"include_authz_decision"`
+	IncludeAuthzDecision bool              `yaml:"include_authz_decision"`
+	Sinks                []AuditSinkConfig `yaml:"sinks"`
 }
 
 func Default() Config {
 	return Config{
-		Server: ServerConfig{ListenAddr: "0.0.0.0:8080"},
-		Store:  StoreConfig{Type: "memory"},
+		Server: ServerConfig{ListenAddr: "0.0.0.0:8080", GRPCRequestTimeoutMS: 5000},
+		Store:  StoreConfig{Type: "memory", IdempotencyTTLSeconds: 86400},
 		Authz: AuthzConfig{
 			Mode:      "builtin",
 			TimeoutMS: 300,
@@ -79,11 +213,22 @@ func Default() Config {
 		Planner: PlannerConfig{
 			ReplyPolicy:      "mention_first",
 			ReplyProbability: 0,
+			Mode:             "builtin",
+			TimeoutMS:        300,
+			FailMode:         "builtin",
 		},
 		Audit: AuditConfig{
-			Sink:                 "jsonl",
-			JSONLPath:            "./arbiter-audit.jsonl",
 			IncludeAuthzDecision: true,
+			Sinks: []AuditSinkConfig{
+				{
+					Type:  "jsonl",
+					Queue: AuditQueueConfig{Size: 1000, Overflow: "block"},
+					JSONL: AuditJSONLSinkConfig{
+						Path:  "./arbiter-audit.jsonl",
+						Chain: AuditChainConfig{Enabled: true, VerifyOnOpen: true},
+					},
+				},
+			},
 		},
 	}
 }
@@ -111,15 +256,37 @@ func (c Config) Validate() error {
 	if c.Server.ListenAddr == "" {
 		return errors.New("server.listen_addr is required")
 	}
+	if c.Server.GRPCListenAddr != "" && c.Server.GRPCRequestTimeoutMS < 0 {
+		return errors.New("server.grpc_request_timeout_ms must be >= 0")
+	}
 
 	switch c.Store.Type {
-	case "memory", "sqlite":
+	case "memory", "sqlite", "redis", "postgres":
 	default:
-		return errors.New("store.type must be memory or sqlite")
+		return errors.New("store.type must be memory, sqlite, redis, or postgres")
 	}
 	if c.Store.Type == "sqlite" && c.Store.SQLitePath == "" {
 		return errors.New("store.sqlite_path is required for sqlite")
 	}
+	if c.Store.Type == "redis" {
+		if len(c.Store.Redis.Addrs) == 0 {
+			return errors.New("store.redis.addrs is required for redis")
+		}
+		if c.Store.Redis.LeaseMS < 0 {
+			return errors.New("store.redis.lease_ms must be >= 0")
+		}
+	}
+	if c.Store.Type == "postgres" {
+		if c.Store.Postgres.DSN == "" {
+			return errors.New("store.postgres.dsn is required for postgres")
+		}
+		if c.Store.Postgres.MaxOpenConn < 0 {
+			return errors.New("store.postgres.max_open_conns must be >= 0")
+		}
+	}
+	if c.Store.IdempotencyTTLSeconds < 0 {
+		return errors.New("store.idempotency_ttl_seconds must be >= 0")
+	}
 
 	switch c.Authz.Mode {
 	case "builtin", "external_http":
@@ -137,6 +304,14 @@ func (c Config) Validate() error {
 	if c.Authz.TimeoutMS <= 0 {
 		return errors.New("authz.timeout_ms must be > 0")
 	}
+	if c.Authz.Cache.Enabled {
+		if c.Authz.Cache.TTLMS <= 0 {
+			return errors.New("authz.cache.ttl_ms must be > 0 when cache is enabled")
+		}
+		if c.Authz.Cache.MaxEntries <= 0 {
+			return errors.New("authz.cache.max_entries must be > 0 when cache is enabled")
+		}
+	}
 
 	if c.Gate.CooldownMS < 0 {
 		return errors.New("gate.cooldown_ms must be >= 0")
@@ -147,6 +322,11 @@ func (c Config) Validate() error {
 	if c.Gate.TenantRateLimitPerMin < 0 {
 		return errors.New("gate.tenant_rate_limit_per_min must be >= 0")
 	}
+	for i, sc := range c.Gate.RateLimit.Scopes {
+		if err := sc.Validate(); err != nil {
+			return fmt.Errorf("gate.rate_limit.scopes[%d]: %w", i, err)
+		}
+	}
 
 	switch c.Planner.ReplyPolicy {
 	case "all", "mention_first", "probabilistic", "reply_only":
@@ -156,12 +336,133 @@ func (c Config) Validate() error {
 	if c.Planner.ReplyProbability < 0 || c.Planner.ReplyProbability > 1 {
 		return errors.New("planner.reply_probability must be between 0 and 1")
 	}
+	switch c.Planner.Mode {
+	case "builtin", "external_http":
+	default:
+		return errors.New("planner.mode must be builtin or external_http")
+	}
+	if c.Planner.Mode == "external_http" && c.Planner.Endpoint == "" {
+		return errors.New("planner.endpoint is required for external_http")
+	}
+	switch c.Planner.FailMode {
+	case "ignore", "builtin", "error":
+	default:
+		return errors.New("planner.fail_mode must be ignore, builtin, or error")
+	}
+	if c.Planner.TimeoutMS <= 0 {
+		return errors.New("planner.timeout_ms must be > 0")
+	}
+
+	if len(c.Audit.Sinks) == 0 {
+		return errors.New("audit.sinks must have at least one entry")
+	}
+	for i, sink := range c.Audit.Sinks {
+		if err := sink.Validate(); err != nil {
+			return fmt.Errorf("audit.sinks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s AuditSinkConfig) Validate() error {
+	switch s.Queue.Overflow {
+	case "", "block", "drop_oldest", "drop_newest":
+	default:
+		return errors.New("queue.overflow must be block, drop_oldest, or drop_newest")
+	}
+	if s.Queue.Size < 0 {
+		return errors.New("queue.size must be >= 0")
+	}
+	if s.Queue.BatchSize < 0 {
+		return errors.New("queue.batch_size must be >= 0")
+	}
+	if s.Queue.FlushIntervalMS < 0 {
+		return errors.New("queue.flush_interval_ms must be >= 0")
+	}
+	if s.Queue.Retry.MaxRetries < 0 {
+		return errors.New("queue.retry.max_retries must be >= 0")
+	}
+	if s.Queue.Retry.MaxRetries > 0 && s.Queue.Retry.InitialBackoffMS <= 0 {
+		return errors.New("queue.retry.initial_backoff_ms must be > 0 when max_retries > 0")
+	}
 
-	if c.Audit.Sink != "jsonl" {
-		return errors.New("audit.sink must be jsonl")
+	switch s.Type {
+	case "jsonl":
+		if s.JSONL.Path == "" {
+			return errors.New("jsonl.path is required")
+		}
+		if s.JSONL.Signing.Enabled {
+			if !s.JSONL.Chain.Enabled {
+				return errors.New("jsonl.signing requires jsonl.chain.enabled")
+			}
+			if s.JSONL.Signing.KeyPath == "" {
+				return errors.New("jsonl.signing.key_path is required when signing is enabled")
+			}
+			if s.JSONL.Signing.IntervalMS <= 0 {
+				return errors.New("jsonl.signing.interval_ms must be > 0 when signing is enabled")
+			}
+		}
+	case "jsonl_rotating":
+		if s.JSONLRotating.Path == "" {
+			return errors.New("jsonl_rotating.path is required")
+		}
+	case "syslog":
+		switch s.Syslog.Network {
+		case "udp", "tcp", "tls":
+		default:
+			return errors.New("syslog.network must be udp, tcp, or tls")
+		}
+		if s.Syslog.Address == "" {
+			return errors.New("syslog.address is required")
+		}
+	case "otlp_logs":
+		if s.OTLPLogs.Endpoint == "" {
+			return errors.New("otlp_logs.endpoint is required")
+		}
+	case "kafka":
+		if len(s.Kafka.Brokers) == 0 {
+			return errors.New("kafka.brokers is required")
+		}
+		if s.Kafka.Topic == "" {
+			return errors.New("kafka.topic is required")
+		}
+	case "webhook":
+		if s.Webhook.URL == "" {
+			return errors.New("webhook.url is required")
+		}
+	case "stdout":
+	default:
+		return fmt.Errorf("type must be one of jsonl, jsonl_rotating, syslog, otlp_logs, kafka, webhook, stdout, got %q", s.Type)
+	}
+	return nil
+}
+
+func (s RateLimitScopeConfig) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	switch s.Scope {
+	case "tenant", "tenant:room", "tenant:actor":
+	default:
+		return fmt.Errorf("scope must be tenant, tenant:room, or tenant:actor, got %q", s.Scope)
 	}
-	if c.Audit.JSONLPath == "" {
-		return errors.New("audit.jsonl_path is required")
+	switch s.Kind {
+	case "token_bucket":
+		if s.RatePerSec <= 0 {
+			return errors.New("rate_per_sec must be > 0 for token_bucket")
+		}
+		if s.Burst <= 0 {
+			return errors.New("burst must be > 0 for token_bucket")
+		}
+	case "sliding_window":
+		if s.WindowMS <= 0 {
+			return errors.New("window_ms must be > 0 for sliding_window")
+		}
+		if s.Limit <= 0 {
+			return errors.New("limit must be > 0 for sliding_window")
+		}
+	default:
+		return fmt.Errorf("kind must be token_bucket or sliding_window, got %q", s.Kind)
 	}
 	return nil
 }
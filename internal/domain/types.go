@@ -105,6 +105,9 @@ type PolicyDecision struct {
 	Stage      string `json:"stage"`
 	Result     string `json:"result"`
 	ReasonCode string `json:"reason_code,omitempty"`
+	// PolicyVersion traces which policy build produced this stage's result,
+	// e.g. planner.ExternalHTTP echoes back the classifier's policy_version.
+	PolicyVersion string `json:"policy_version,omitempty"`
 }
 
 type GenerationResult struct {
@@ -4,23 +4,29 @@ import (
 	"time"
 
 	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/ratelimit"
 	"github.com/viasnake/arbiter/internal/store"
 )
 
 type Result struct {
 	Allowed    bool
 	ReasonCode string
+	RetryAfter time.Duration
 }
 
 type Evaluator struct {
-	cfg config.GateConfig
+	cfg     config.GateConfig
+	limiter *ratelimit.Limiter
 }
 
-func NewEvaluator(cfg config.GateConfig) *Evaluator {
-	return &Evaluator{cfg: cfg}
+func NewEvaluator(cfg config.GateConfig, st store.Store) *Evaluator {
+	return &Evaluator{
+		cfg:     cfg,
+		limiter: ratelimit.New(st, cfg.RateLimit.Scopes),
+	}
 }
 
-func (e *Evaluator) Evaluate(room store.RoomState, eventTS time.Time, tenantCount int) Result {
+func (e *Evaluator) Evaluate(room store.RoomState, eventTS time.Time, tenantCount int, tenantID, roomID, actorID string) Result {
 	if room.Generating {
 		return Result{Allowed: false, ReasonCode: "gate_generating_lock"}
 	}
@@ -40,5 +46,9 @@ func (e *Evaluator) Evaluate(room store.RoomState, eventTS time.Time, tenantCoun
 		return Result{Allowed: false, ReasonCode: "gate_tenant_rate_limit"}
 	}
 
+	if rl := e.limiter.Evaluate(tenantID, roomID, actorID, eventTS); !rl.Allowed {
+		return Result{Allowed: false, ReasonCode: rl.ReasonCode, RetryAfter: rl.RetryAfter}
+	}
+
 	return Result{Allowed: true}
 }
@@ -0,0 +1,46 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/viasnake/arbiter/internal/app"
+	"github.com/viasnake/arbiter/internal/audit"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// NewGRPCServer builds a *grpc.Server exposing the arbiter.v0.Arbiter
+// service backed by svc, with a chained interceptor stack run in this order:
+// panic recovery, x-correlation-id propagation, request-timeout enforcement,
+// then tenant extraction and rate limiting. tenantRateLimitPerMin <= 0
+// disables the limiter; requestTimeout <= 0 disables the per-request
+// deadline. WatchPlans' stream handler never sees the unary chain (and so
+// never gets the per-request timeout, which wouldn't make sense for a
+// long-lived stream) -- it instead goes through the streaming variant of
+// the same recovery/correlation/tenant-auth interceptors, so it gets the
+// same panic recovery and authenticated-tenant derivation unary RPCs do.
+func NewGRPCServer(svc *app.Service, al audit.Logger, st store.Store, tenantRateLimitPerMin int, requestTimeout time.Duration) *grpc.Server {
+	limiter := newTenantRateLimiter(st, tenantRateLimitPerMin)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			recoveryUnaryInterceptor(al),
+			correlationUnaryInterceptor(),
+			timeoutUnaryInterceptor(requestTimeout),
+			tenantAuthUnaryInterceptor(limiter),
+		),
+		grpc.ChainStreamInterceptor(
+			recoveryStreamInterceptor(al),
+			correlationStreamInterceptor(),
+			tenantAuthStreamInterceptor(limiter),
+		),
+	)
+	RegisterArbiterServer(srv, NewServer(svc))
+	return srv
+}
@@ -0,0 +1,223 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/viasnake/arbiter/internal/audit"
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+type correlationIDKey struct{}
+type tenantIDKey struct{}
+
+func withCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+func correlationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	if id == "" {
+		// The recovery interceptors run ahead of the correlation ones, so
+		// fall back to the x-correlation-id metadata itself.
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get("x-correlation-id"); len(vals) > 0 {
+				id = vals[0]
+			}
+		}
+	}
+	if id == "" {
+		return "-"
+	}
+	return id
+}
+
+func withTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, id)
+}
+
+func tenantIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantIDKey{}).(string)
+	return id
+}
+
+// recoveryUnaryInterceptor turns a panic in a handler into codes.Internal
+// instead of killing the connection, and records the recovery to the audit
+// log the same way a denied request would be, tagged reason_code
+// internal_panic so it's searchable alongside gate/authz denials.
+func recoveryUnaryInterceptor(al audit.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				correlationID := correlationIDFromContext(ctx)
+				_ = al.Append(audit.Record{
+					AuditID:       domain.NewActionID(correlationID, "grpc_panic", 0),
+					CorrelationID: correlationID,
+					Action:        info.FullMethod,
+					Result:        "recovered",
+					ReasonCode:    "internal_panic",
+					TS:            time.Now().UTC().Format(time.RFC3339Nano),
+				})
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// correlationUnaryInterceptor propagates the x-correlation-id metadata value
+// (if any) into the context so downstream interceptors and handlers can
+// stamp it onto audit.Record.CorrelationID.
+func correlationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		correlationID := "-"
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get("x-correlation-id"); len(vals) > 0 && vals[0] != "" {
+				correlationID = vals[0]
+			}
+		}
+		return handler(withCorrelationID(ctx, correlationID), req)
+	}
+}
+
+// timeoutUnaryInterceptor bounds how long a unary RPC may run. A timeout <=
+// 0 disables it. WatchPlans' long-lived stream goes through the separate
+// stream interceptor chain and never sees this one.
+func timeoutUnaryInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// tenantAuthUnaryInterceptor extracts the calling tenant from the peer's
+// mTLS SPIFFE ID or, failing that, a bearer token, then enforces the same
+// per-tenant rate budget the HTTP path's gate.Evaluator applies.
+func tenantAuthUnaryInterceptor(limiter *tenantRateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		tenantID, err := extractTenantID(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = withTenantID(ctx, tenantID)
+
+		if !limiter.Allow(tenantID) {
+			return nil, status.Error(codes.ResourceExhausted, "tenant rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// wrappedServerStream overrides grpc.ServerStream's Context so a stream
+// interceptor can inject values (correlation ID, authenticated tenant) the
+// same way a unary interceptor threads them through ctx.
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedServerStream) Context() context.Context {
+	return w.ctx
+}
+
+// recoveryStreamInterceptor is recoveryUnaryInterceptor's streaming
+// counterpart: a panic inside a stream handler (e.g. WatchPlans) is turned
+// into codes.Internal and recorded to the audit log instead of killing the
+// connection.
+func recoveryStreamInterceptor(al audit.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				correlationID := correlationIDFromContext(ss.Context())
+				_ = al.Append(audit.Record{
+					AuditID:       domain.NewActionID(correlationID, "grpc_panic", 0),
+					CorrelationID: correlationID,
+					Action:        info.FullMethod,
+					Result:        "recovered",
+					ReasonCode:    "internal_panic",
+					TS:            time.Now().UTC().Format(time.RFC3339Nano),
+				})
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// correlationStreamInterceptor is correlationUnaryInterceptor's streaming
+// counterpart.
+func correlationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		correlationID := "-"
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get("x-correlation-id"); len(vals) > 0 && vals[0] != "" {
+				correlationID = vals[0]
+			}
+		}
+		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: withCorrelationID(ctx, correlationID)})
+	}
+}
+
+// tenantAuthStreamInterceptor is tenantAuthUnaryInterceptor's streaming
+// counterpart: it authenticates the caller the same way (mTLS SPIFFE ID or
+// bearer token) and enforces the same per-tenant rate budget, then stores
+// the authenticated tenant on the stream's context so a handler like
+// WatchPlans never has to trust a tenant_id the caller put in the request
+// body.
+func tenantAuthStreamInterceptor(limiter *tenantRateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		tenantID, err := extractTenantID(ctx)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !limiter.Allow(tenantID) {
+			return status.Error(codes.ResourceExhausted, "tenant rate limit exceeded")
+		}
+		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: withTenantID(ctx, tenantID)})
+	}
+}
+
+// extractTenantID looks for a spiffe://<trust-domain>/tenant/<tenant_id>/...
+// URI SAN on the peer's mTLS certificate first, and falls back to treating a
+// bearer token's value as the tenant ID.
+func extractTenantID(ctx context.Context) (string, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			for _, cert := range tlsInfo.State.PeerCertificates {
+				for _, uri := range cert.URIs {
+					if uri.Scheme != "spiffe" {
+						continue
+					}
+					parts := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+					if len(parts) >= 2 && parts[0] == "tenant" && parts[1] != "" {
+						return parts[1], nil
+					}
+				}
+			}
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if tenantID, ok := strings.CutPrefix(v, "Bearer "); ok && tenantID != "" {
+				return tenantID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no tenant credentials found (mTLS SPIFFE ID or bearer token required)")
+}
@@ -0,0 +1,174 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/viasnake/arbiter/internal/audit"
+	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+func TestExtractTenantIDFromBearerToken(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t1"))
+
+	tenantID, err := extractTenantID(ctx)
+	if err != nil {
+		t.Fatalf("extractTenantID: %v", err)
+	}
+	if tenantID != "t1" {
+		t.Fatalf("expected tenant t1, got %q", tenantID)
+	}
+}
+
+func TestExtractTenantIDMissingCredentials(t *testing.T) {
+	if _, err := extractTenantID(context.Background()); err == nil {
+		t.Fatal("expected an error with no mTLS peer and no bearer token")
+	}
+}
+
+func TestRecoveryInterceptorConvertsPanicToInternal(t *testing.T) {
+	al := &memoryAuditSink{}
+	interceptor := recoveryUnaryInterceptor(al)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/arbiter.v0.Arbiter/SubmitEvent"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	if err == nil {
+		t.Fatal("expected an error after a recovered panic")
+	}
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", status.Code(err))
+	}
+	if len(al.records) != 1 || al.records[0].ReasonCode != "internal_panic" {
+		t.Fatalf("expected one internal_panic audit record, got %+v", al.records)
+	}
+}
+
+func TestTenantAuthInterceptorEnforcesRateLimit(t *testing.T) {
+	st := &fixedRateStore{count: 5}
+	limiter := newTenantRateLimiter(st, 5)
+	interceptor := tenantAuthUnaryInterceptor(limiter)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/arbiter.v0.Arbiter/SubmitEvent"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	_, err := interceptor(ctx, nil, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected codes.ResourceExhausted, got %v", err)
+	}
+}
+
+func TestRecoveryStreamInterceptorConvertsPanicToInternal(t *testing.T) {
+	al := &memoryAuditSink{}
+	interceptor := recoveryStreamInterceptor(al)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/arbiter.v0.Arbiter/WatchPlans"}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		panic("boom")
+	}
+
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, handler)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", err)
+	}
+	if len(al.records) != 1 || al.records[0].ReasonCode != "internal_panic" {
+		t.Fatalf("expected one internal_panic audit record, got %+v", al.records)
+	}
+}
+
+func TestTenantAuthStreamInterceptorRejectsMissingCredentials(t *testing.T) {
+	limiter := newTenantRateLimiter(&fixedRateStore{}, 0)
+	interceptor := tenantAuthStreamInterceptor(limiter)
+
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected codes.Unauthenticated with no tenant credentials, got %v", err)
+	}
+}
+
+func TestTenantAuthStreamInterceptorStoresAuthenticatedTenantOnContext(t *testing.T) {
+	limiter := newTenantRateLimiter(&fixedRateStore{count: 0}, 5)
+	interceptor := tenantAuthStreamInterceptor(limiter)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t1"))
+	var observed string
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		observed = tenantIDFromContext(ss.Context())
+		return nil
+	}
+
+	if err := interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler); err != nil {
+		t.Fatalf("tenantAuthStreamInterceptor: %v", err)
+	}
+	if observed != "t1" {
+		t.Fatalf("expected authenticated tenant t1 on the stream context, got %q", observed)
+	}
+}
+
+func TestTenantAuthStreamInterceptorEnforcesRateLimit(t *testing.T) {
+	st := &fixedRateStore{count: 5}
+	limiter := newTenantRateLimiter(st, 5)
+	interceptor := tenantAuthStreamInterceptor(limiter)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t1"))
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	err := interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected codes.ResourceExhausted, got %v", err)
+	}
+}
+
+// fakeServerStream is a minimal grpc.ServerStream double that only needs to
+// answer Context() for interceptor tests.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
+
+type memoryAuditSink struct {
+	records []audit.Record
+}
+
+func (m *memoryAuditSink) Append(r audit.Record) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func (m *memoryAuditSink) Close() error { return nil }
+
+// fixedRateStore is a minimal store.Store double that only needs to answer
+// rate-limit questions for these tests.
+type fixedRateStore struct {
+	count int
+}
+
+func (f *fixedRateStore) GetIdempotency(string, string) (domain.ResponsePlan, bool) {
+	return domain.ResponsePlan{}, false
+}
+func (f *fixedRateStore) PutIdempotency(string, string, domain.ResponsePlan)   {}
+func (f *fixedRateStore) GetRoomState(string, string) store.RoomState          { return store.RoomState{} }
+func (f *fixedRateStore) PutPendingGeneration(store.PendingGeneration)         {}
+func (f *fixedRateStore) ConsumePendingGeneration(string, string, time.Time) (store.PendingGeneration, bool) {
+	return store.PendingGeneration{}, false
+}
+func (f *fixedRateStore) TenantRateCount(string, int64) int { return f.count }
+func (f *fixedRateStore) IncrementTenantRate(string, int64) {}
+func (f *fixedRateStore) TokenBucketTake(string, float64, int, int, time.Time) (bool, time.Duration) {
+	return true, 0
+}
+func (f *fixedRateStore) SlidingWindowAllow(string, time.Duration, int, time.Time) bool { return true }
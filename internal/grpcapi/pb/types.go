@@ -0,0 +1,84 @@
+// Package pb holds the Go types for api/proto/arbiter/v0/arbiter.proto.
+//
+// arbiter has no protoc-gen-go toolchain wired into the build yet, so these
+// are hand-maintained structs kept in lockstep with the .proto by hand
+// rather than generated; internal/grpcapi registers a JSON codec (see
+// codec.go) so the gRPC server can marshal them without depending on the
+// protobuf runtime. Swap this package for protoc-gen-go output once that
+// toolchain lands. The JSON tags use the .proto field names; the Go field
+// names (e.g. EventID, TS), which tags carry omitempty, and the value rather
+// than pointer message fields differ from what protoc-gen-go would emit.
+package pb
+
+type Actor struct {
+	Type  string   `json:"type"`
+	ID    string   `json:"id"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type EventContent struct {
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`
+	ReplyTo string `json:"reply_to,omitempty"`
+}
+
+type SubmitEventRequest struct {
+	V        int32        `json:"v"`
+	EventID  string       `json:"event_id"`
+	TenantID string       `json:"tenant_id"`
+	Source   string       `json:"source"`
+	RoomID   string       `json:"room_id"`
+	Actor    Actor        `json:"actor"`
+	Content  EventContent `json:"content"`
+	TS       string       `json:"ts"`
+}
+
+type SubmitGenerationRequest struct {
+	V        int32  `json:"v"`
+	PlanID   string `json:"plan_id"`
+	ActionID string `json:"action_id"`
+	TenantID string `json:"tenant_id"`
+	Text     string `json:"text"`
+	TraceID  string `json:"trace_id,omitempty"`
+}
+
+type Action struct {
+	Type        string `json:"type"`
+	ActionID    string `json:"action_id"`
+	TargetJSON  string `json:"target_json,omitempty"`
+	PayloadJSON string `json:"payload_json,omitempty"`
+}
+
+type PolicyDecision struct {
+	Stage         string `json:"stage"`
+	Result        string `json:"result"`
+	ReasonCode    string `json:"reason_code,omitempty"`
+	PolicyVersion string `json:"policy_version,omitempty"`
+}
+
+type ResponsePlan struct {
+	V               int32            `json:"v"`
+	PlanID          string           `json:"plan_id"`
+	TenantID        string           `json:"tenant_id"`
+	RoomID          string           `json:"room_id"`
+	Actions         []Action         `json:"actions"`
+	PolicyDecisions []PolicyDecision `json:"policy_decisions,omitempty"`
+}
+
+type HealthzRequest struct{}
+
+type HealthzResponse struct {
+	Status string `json:"status"`
+}
+
+type RecordActionResultRequest struct {
+	TenantID      string `json:"tenant_id"`
+	CorrelationID string `json:"correlation_id"`
+	ReasonCode    string `json:"reason_code,omitempty"`
+}
+
+type RecordActionResultResponse struct{}
+
+type WatchPlansRequest struct {
+	TenantID string `json:"tenant_id"`
+}
@@ -0,0 +1,37 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+// tenantRateLimiter reuses the same per-tenant, per-minute-bucket counters
+// gate.Evaluator checks on the HTTP path (store.TenantRateCount /
+// IncrementTenantRate), so a tenant's budget is shared across both
+// transports. A limit <= 0 disables limiting.
+type tenantRateLimiter struct {
+	store store.Store
+	limit int
+	nowFn func() time.Time
+}
+
+func newTenantRateLimiter(st store.Store, limit int) *tenantRateLimiter {
+	return &tenantRateLimiter{
+		store: st,
+		limit: limit,
+		nowFn: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (r *tenantRateLimiter) Allow(tenantID string) bool {
+	if r == nil || r.limit <= 0 {
+		return true
+	}
+	bucket := r.nowFn().Unix() / 60
+	if r.store.TenantRateCount(tenantID, bucket) >= r.limit {
+		return false
+	}
+	r.store.IncrementTenantRate(tenantID, bucket)
+	return true
+}
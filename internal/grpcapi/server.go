@@ -0,0 +1,184 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/viasnake/arbiter/internal/app"
+	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/grpcapi/pb"
+)
+
+// Server implements the arbiter.v0.Arbiter RPCs defined in
+// api/proto/arbiter/v0/arbiter.proto against the same *app.Service the HTTP
+// API uses, so idempotency, gate, and audit behavior are identical
+// regardless of transport.
+type Server struct {
+	svc *app.Service
+}
+
+func NewServer(svc *app.Service) *Server {
+	return &Server{svc: svc}
+}
+
+// requireAuthenticatedTenant returns the tenant tenantAuthUnaryInterceptor /
+// tenantAuthStreamInterceptor derived from the caller's mTLS SPIFFE ID or
+// bearer token, rejecting a reqTenantID (a request body's tenant_id) that
+// disagrees with it. Handlers must use the returned tenant instead of
+// trusting reqTenantID directly -- otherwise a caller authenticated as one
+// tenant could submit events, generations, or action-results as another.
+func requireAuthenticatedTenant(ctx context.Context, reqTenantID string) (string, error) {
+	tenantID := tenantIDFromContext(ctx)
+	if tenantID == "" {
+		return "", status.Error(codes.Unauthenticated, "no authenticated tenant on request context")
+	}
+	if reqTenantID != "" && reqTenantID != tenantID {
+		return "", status.Error(codes.PermissionDenied, "tenant_id does not match authenticated tenant")
+	}
+	return tenantID, nil
+}
+
+func (s *Server) SubmitEvent(ctx context.Context, req *pb.SubmitEventRequest) (*pb.ResponsePlan, error) {
+	tenantID, err := requireAuthenticatedTenant(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := domain.Event{
+		V:        domain.ContractVersion,
+		EventID:  req.EventID,
+		TenantID: tenantID,
+		Source:   req.Source,
+		RoomID:   req.RoomID,
+		Actor: domain.Actor{
+			Type:  req.Actor.Type,
+			ID:    req.Actor.ID,
+			Roles: req.Actor.Roles,
+		},
+		Content: domain.EventContent{
+			Type: req.Content.Type,
+			Text: req.Content.Text,
+		},
+		TS: req.TS,
+	}
+	if req.Content.ReplyTo != "" {
+		replyTo := req.Content.ReplyTo
+		ev.Content.ReplyTo = &replyTo
+	}
+
+	plan, err := s.svc.ProcessEvent(ctx, ev)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toProtoPlan(plan), nil
+}
+
+func (s *Server) SubmitGeneration(ctx context.Context, req *pb.SubmitGenerationRequest) (*pb.ResponsePlan, error) {
+	tenantID, err := requireAuthenticatedTenant(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := domain.GenerationResult{
+		V:        domain.ContractVersion,
+		PlanID:   req.PlanID,
+		ActionID: req.ActionID,
+		TenantID: tenantID,
+		Text:     req.Text,
+	}
+	if req.TraceID != "" {
+		traceID := req.TraceID
+		result.TraceID = &traceID
+	}
+
+	plan, err := s.svc.ProcessGeneration(ctx, result)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toProtoPlan(plan), nil
+}
+
+func (s *Server) Healthz(_ context.Context, _ *pb.HealthzRequest) (*pb.HealthzResponse, error) {
+	return &pb.HealthzResponse{Status: "ok"}, nil
+}
+
+func (s *Server) RecordActionResult(ctx context.Context, req *pb.RecordActionResultRequest) (*pb.RecordActionResultResponse, error) {
+	tenantID, err := requireAuthenticatedTenant(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.svc.RecordActionResult(tenantID, req.CorrelationID, req.ReasonCode); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.RecordActionResultResponse{}, nil
+}
+
+// WatchPlans subscribes to the caller's plan feed and streams every
+// ResponsePlan the service produces for that tenant until the client
+// disconnects. The tenant is the one tenantAuthStreamInterceptor derived
+// from the caller's mTLS SPIFFE ID or bearer token, never req.TenantID --
+// trusting a tenant_id in the request body would let any caller subscribe
+// to another tenant's plan feed. A req.TenantID that disagrees with the
+// authenticated tenant is rejected outright rather than silently ignored.
+func (s *Server) WatchPlans(req *pb.WatchPlansRequest, stream Arbiter_WatchPlansServer) error {
+	tenantID, err := requireAuthenticatedTenant(stream.Context(), req.TenantID)
+	if err != nil {
+		return err
+	}
+
+	sub := s.svc.WatchPlans(tenantID)
+	defer sub.Cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case plan, ok := <-sub.Plans:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toProtoPlan(plan)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoPlan(plan domain.ResponsePlan) *pb.ResponsePlan {
+	actions := make([]pb.Action, 0, len(plan.Actions))
+	for _, a := range plan.Actions {
+		actions = append(actions, pb.Action{
+			Type:        a.Type,
+			ActionID:    a.ActionID,
+			TargetJSON:  marshalOrEmpty(a.Target),
+			PayloadJSON: marshalOrEmpty(a.Payload),
+		})
+	}
+	decisions := make([]pb.PolicyDecision, 0, len(plan.PolicyDecisions))
+	for _, d := range plan.PolicyDecisions {
+		decisions = append(decisions, pb.PolicyDecision{Stage: d.Stage, Result: d.Result, ReasonCode: d.ReasonCode, PolicyVersion: d.PolicyVersion})
+	}
+	return &pb.ResponsePlan{
+		V:               int32(plan.V),
+		PlanID:          plan.PlanID,
+		TenantID:        plan.TenantID,
+		RoomID:          plan.RoomID,
+		Actions:         actions,
+		PolicyDecisions: decisions,
+	}
+}
+
+func marshalOrEmpty(v map[string]interface{}) string {
+	if len(v) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
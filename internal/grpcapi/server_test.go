@@ -0,0 +1,206 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/viasnake/arbiter/internal/app"
+	"github.com/viasnake/arbiter/internal/audit"
+	"github.com/viasnake/arbiter/internal/authz"
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/grpcapi/pb"
+	"github.com/viasnake/arbiter/internal/planner"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+type discardAudit struct{}
+
+func (discardAudit) Append(audit.Record) error { return nil }
+func (discardAudit) Close() error              { return nil }
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := config.Default()
+	st := store.NewMemoryStore()
+	svc := app.NewService(cfg, st, authz.BuiltinAllowAll{}, planner.New(cfg.Planner, st), discardAudit{})
+	return NewServer(svc)
+}
+
+func TestServerSubmitEventMirrorsHTTPShape(t *testing.T) {
+	s := newTestServer(t)
+
+	resp, err := s.SubmitEvent(withTenantID(context.Background(), "t1"), &pb.SubmitEventRequest{
+		V:        0,
+		EventID:  "ev1",
+		TenantID: "t1",
+		Source:   "slack",
+		RoomID:   "r1",
+		Actor:    pb.Actor{Type: "human", ID: "u1"},
+		Content:  pb.EventContent{Type: "text", Text: "hello @arbiter"},
+		TS:       "2026-07-26T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("SubmitEvent: %v", err)
+	}
+	if resp.TenantID != "t1" || resp.RoomID != "r1" {
+		t.Fatalf("unexpected plan: %+v", resp)
+	}
+	if len(resp.Actions) != 1 {
+		t.Fatalf("expected exactly one action, got %d", len(resp.Actions))
+	}
+}
+
+func TestServerSubmitEventRejectsInvalidRequest(t *testing.T) {
+	s := newTestServer(t)
+
+	if _, err := s.SubmitEvent(withTenantID(context.Background(), "t1"), &pb.SubmitEventRequest{}); err == nil {
+		t.Fatal("expected an error for a request missing required fields")
+	}
+}
+
+func TestServerSubmitEventRejectsUnauthenticatedCaller(t *testing.T) {
+	s := newTestServer(t)
+
+	if _, err := s.SubmitEvent(context.Background(), &pb.SubmitEventRequest{TenantID: "t1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected codes.Unauthenticated with no authenticated tenant, got %v", err)
+	}
+}
+
+func TestServerSubmitEventRejectsMismatchedTenant(t *testing.T) {
+	s := newTestServer(t)
+
+	_, err := s.SubmitEvent(withTenantID(context.Background(), "t1"), &pb.SubmitEventRequest{
+		V:        0,
+		EventID:  "ev1",
+		TenantID: "t2",
+		Source:   "slack",
+		RoomID:   "r1",
+		Actor:    pb.Actor{Type: "human", ID: "u1"},
+		Content:  pb.EventContent{Type: "text", Text: "hello @arbiter"},
+		TS:       "2026-07-26T00:00:00Z",
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected codes.PermissionDenied for a body tenant_id that disagrees with the authenticated tenant, got %v", err)
+	}
+}
+
+func TestServerHealthz(t *testing.T) {
+	s := newTestServer(t)
+
+	resp, err := s.Healthz(context.Background(), &pb.HealthzRequest{})
+	if err != nil {
+		t.Fatalf("Healthz: %v", err)
+	}
+	if resp.Status != "ok" {
+		t.Fatalf("expected status ok, got %q", resp.Status)
+	}
+}
+
+func TestServerRecordActionResultRejectsMissingFields(t *testing.T) {
+	s := newTestServer(t)
+
+	if _, err := s.RecordActionResult(withTenantID(context.Background(), "t1"), &pb.RecordActionResultRequest{}); err == nil {
+		t.Fatal("expected an error for a request missing tenant_id and correlation_id")
+	}
+}
+
+func TestServerRecordActionResultRejectsMismatchedTenant(t *testing.T) {
+	s := newTestServer(t)
+
+	_, err := s.RecordActionResult(withTenantID(context.Background(), "t1"), &pb.RecordActionResultRequest{
+		TenantID:      "t2",
+		CorrelationID: "c1",
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected codes.PermissionDenied for a body tenant_id that disagrees with the authenticated tenant, got %v", err)
+	}
+}
+
+func TestServerWatchPlansStreamsProducedPlans(t *testing.T) {
+	s := newTestServer(t)
+	stream := newFakeWatchPlansStream(withTenantID(context.Background(), "t1"))
+
+	done := make(chan error, 1)
+	go func() { done <- s.WatchPlans(&pb.WatchPlansRequest{TenantID: "t1"}, stream) }()
+	time.Sleep(50 * time.Millisecond) // let the subscription register before the plan is produced
+
+	if _, err := s.SubmitEvent(withTenantID(context.Background(), "t1"), &pb.SubmitEventRequest{
+		V:        0,
+		EventID:  "ev1",
+		TenantID: "t1",
+		Source:   "slack",
+		RoomID:   "r1",
+		Actor:    pb.Actor{Type: "human", ID: "u1"},
+		Content:  pb.EventContent{Type: "text", Text: "hello @arbiter"},
+		TS:       "2026-07-26T00:00:00Z",
+	}); err != nil {
+		t.Fatalf("SubmitEvent: %v", err)
+	}
+
+	select {
+	case plan := <-stream.sent:
+		if plan.TenantID != "t1" {
+			t.Fatalf("expected plan for t1, got %+v", plan)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for WatchPlans to deliver the produced plan")
+	}
+
+	stream.cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for WatchPlans to return after cancellation")
+	}
+}
+
+func TestServerWatchPlansRequiresAuthenticatedTenant(t *testing.T) {
+	s := newTestServer(t)
+	stream := newFakeWatchPlansStream(context.Background())
+
+	err := s.WatchPlans(&pb.WatchPlansRequest{TenantID: "t1"}, stream)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected codes.Unauthenticated with no authenticated tenant on the stream context, got %v", err)
+	}
+}
+
+func TestServerWatchPlansRejectsMismatchedTenant(t *testing.T) {
+	s := newTestServer(t)
+	stream := newFakeWatchPlansStream(withTenantID(context.Background(), "t1"))
+
+	err := s.WatchPlans(&pb.WatchPlansRequest{TenantID: "t2"}, stream)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected codes.PermissionDenied for a tenant_id that disagrees with the authenticated tenant, got %v", err)
+	}
+}
+
+// fakeWatchPlansStream is a minimal Arbiter_WatchPlansServer double that
+// records sent plans on a channel instead of writing them to the wire.
+type fakeWatchPlansStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+	sent   chan *pb.ResponsePlan
+}
+
+func newFakeWatchPlansStream(ctx context.Context) *fakeWatchPlansStream {
+	ctx, cancel := context.WithCancel(ctx)
+	return &fakeWatchPlansStream{ctx: ctx, cancel: cancel, sent: make(chan *pb.ResponsePlan, 1)}
+}
+
+func (f *fakeWatchPlansStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeWatchPlansStream) Send(plan *pb.ResponsePlan) error {
+	f.sent <- plan
+	return nil
+}
@@ -0,0 +1,128 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/viasnake/arbiter/internal/grpcapi/pb"
+)
+
+// arbiterServer is the handler-side contract for the arbiter.v0.Arbiter
+// service, satisfied by *Server.
+type arbiterServer interface {
+	SubmitEvent(context.Context, *pb.SubmitEventRequest) (*pb.ResponsePlan, error)
+	SubmitGeneration(context.Context, *pb.SubmitGenerationRequest) (*pb.ResponsePlan, error)
+	RecordActionResult(context.Context, *pb.RecordActionResultRequest) (*pb.RecordActionResultResponse, error)
+	WatchPlans(*pb.WatchPlansRequest, Arbiter_WatchPlansServer) error
+	Healthz(context.Context, *pb.HealthzRequest) (*pb.HealthzResponse, error)
+}
+
+// Arbiter_WatchPlansServer is the server-streaming handle WatchPlans sends
+// ResponsePlans through, named to match what protoc-gen-go-grpc would emit
+// for a `stream ResponsePlan` return.
+type Arbiter_WatchPlansServer interface {
+	Send(*pb.ResponsePlan) error
+	grpc.ServerStream
+}
+
+type arbiterWatchPlansServer struct {
+	grpc.ServerStream
+}
+
+func (x *arbiterWatchPlansServer) Send(m *pb.ResponsePlan) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RegisterArbiterServer registers srv against s the way protoc-gen-go-grpc's
+// generated RegisterArbiterServer would.
+func RegisterArbiterServer(s *grpc.Server, srv arbiterServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "arbiter.v0.Arbiter",
+	HandlerType: (*arbiterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SubmitEvent", Handler: submitEventHandler},
+		{MethodName: "SubmitGeneration", Handler: submitGenerationHandler},
+		{MethodName: "RecordActionResult", Handler: recordActionResultHandler},
+		{MethodName: "Healthz", Handler: healthzHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPlans",
+			Handler:       watchPlansHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/arbiter/v0/arbiter.proto",
+}
+
+func submitEventHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pb.SubmitEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(arbiterServer).SubmitEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/arbiter.v0.Arbiter/SubmitEvent"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(arbiterServer).SubmitEvent(ctx, req.(*pb.SubmitEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func submitGenerationHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pb.SubmitGenerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(arbiterServer).SubmitGeneration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/arbiter.v0.Arbiter/SubmitGeneration"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(arbiterServer).SubmitGeneration(ctx, req.(*pb.SubmitGenerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func healthzHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pb.HealthzRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(arbiterServer).Healthz(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/arbiter.v0.Arbiter/Healthz"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(arbiterServer).Healthz(ctx, req.(*pb.HealthzRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func recordActionResultHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pb.RecordActionResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(arbiterServer).RecordActionResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/arbiter.v0.Arbiter/RecordActionResult"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(arbiterServer).RecordActionResult(ctx, req.(*pb.RecordActionResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func watchPlansHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(pb.WatchPlansRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(arbiterServer).WatchPlans(in, &arbiterWatchPlansServer{stream})
+}
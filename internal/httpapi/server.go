@@ -4,13 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/viasnake/arbiter/internal/app"
 	"github.com/viasnake/arbiter/internal/domain"
 )
 
+// sseHeartbeatInterval governs how often handlePlanStream writes a comment
+// line to keep idle connections (and any intermediate proxies) from timing
+// out while waiting for the next plan or action-result event.
+const sseHeartbeatInterval = 15 * time.Second
+
 type Server struct {
 	svc *app.Service
 }
@@ -26,6 +33,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/v0/events", s.handleEvents)
 	mux.HandleFunc("/v0/generations", s.handleGenerations)
 	mux.HandleFunc("/v0/action-results", s.handleActionResults)
+	mux.HandleFunc("/v0/plans/stream", s.handlePlanStream)
 	return mux
 }
 
@@ -34,9 +42,10 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 		writeMethodNotAllowed(w)
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("ok"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"metrics": map[string]interface{}{"audit_sinks": s.svc.AuditMetrics()},
+	})
 }
 
 func (s *Server) handleContracts(w http.ResponseWriter, r *http.Request) {
@@ -72,9 +81,36 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "validation_error", err.Error())
 		return
 	}
+	if retryAfterMS, ok := retryAfterMillis(plan); ok {
+		w.Header().Set("Retry-After", strconv.FormatInt((retryAfterMS+999)/1000, 10))
+	}
 	writeJSON(w, http.StatusOK, plan)
 }
 
+// retryAfterMillis extracts the rate-limited gate's retry_after_ms payload
+// field, if the plan's first action carries one. A freshly-computed plan
+// stores it as an int64 (service.go's gateResult.RetryAfter.Milliseconds()),
+// but a plan reconstructed from an idempotency replay came back through a
+// JSON-backed store (SQLite/Redis/Postgres), where it deserializes as
+// float64 or json.Number -- so this accepts all three rather than trusting
+// the in-process type.
+func retryAfterMillis(plan domain.ResponsePlan) (int64, bool) {
+	if len(plan.Actions) == 0 {
+		return 0, false
+	}
+	switch v := plan.Actions[0].Payload["retry_after_ms"].(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	case json.Number:
+		ms, err := v.Int64()
+		return ms, err == nil
+	default:
+		return 0, false
+	}
+}
+
 func (s *Server) handleGenerations(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeMethodNotAllowed(w)
@@ -117,6 +153,93 @@ func (s *Server) handleActionResults(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handlePlanStream serves a Server-Sent Events feed of every ResponsePlan
+// and action-result correlation produced for tenant_id (optionally
+// filtered to room_id), so a worker process can subscribe once instead of
+// polling. A client that reconnects with a Last-Event-ID header resumes
+// from the broker's buffered backlog rather than missing events in between.
+func (s *Server) handlePlanStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	tenantID := r.URL.Query().Get("tenant_id")
+	if tenantID == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "tenant_id is required")
+		return
+	}
+	roomID := r.URL.Query().Get("room_id")
+
+	var lastEventID uint64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "Last-Event-ID must be a non-negative integer")
+			return
+		}
+		lastEventID = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming_unsupported", "server does not support streaming")
+		return
+	}
+
+	sub := s.svc.WatchPlanStream(tenantID, roomID, lastEventID)
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSEEvent marshals ev's payload (a ResponsePlan for plan events, an
+// ActionResultEvent for action-result events) and writes it as one SSE
+// frame: id/event/data fields terminated by a blank line.
+func writeSSEEvent(w http.ResponseWriter, ev app.StreamEvent) error {
+	var payload interface{}
+	switch ev.Kind {
+	case app.StreamEventActionResult:
+		payload = ev.ActionResult
+	default:
+		payload = ev.Plan
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Kind, data)
+	return err
+}
+
 func decodeStrictJSON(r *http.Request, out interface{}) error {
 	defer r.Body.Close()
 	dec := json.NewDecoder(r.Body)
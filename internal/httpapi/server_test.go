@@ -1,10 +1,13 @@
 package httpapi
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/viasnake/arbiter/internal/audit"
 	"github.com/viasnake/arbiter/internal/authz"
 	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
 	"github.com/viasnake/arbiter/internal/planner"
 	"github.com/viasnake/arbiter/internal/store"
 )
@@ -20,7 +24,11 @@ func testServer(t *testing.T) http.Handler {
 	t.Helper()
 	cfg := config.Default()
 	cfg.Planner.ReplyPolicy = "all"
+	return testServerWithConfig(t, cfg)
+}
 
+func testServerWithConfig(t *testing.T, cfg config.Config) http.Handler {
+	t.Helper()
 	st := store.NewMemoryStore()
 	az := authz.BuiltinAllowAll{}
 
@@ -30,7 +38,7 @@ func testServer(t *testing.T) http.Handler {
 	}
 	t.Cleanup(func() { _ = af.Close() })
 
-	svc := app.NewService(cfg, st, az, planner.New(cfg.Planner), af)
+	svc := app.NewService(cfg, st, az, planner.New(cfg.Planner, st), af)
 	return NewServer(svc).Handler()
 }
 
@@ -79,3 +87,214 @@ func TestEventsEndpoint(t *testing.T) {
 		t.Fatalf("response must have plan_id")
 	}
 }
+
+func TestRetryAfterMillisTolerantOfReplayedNumberTypes(t *testing.T) {
+	planWith := func(v interface{}) domain.ResponsePlan {
+		return domain.ResponsePlan{Actions: []domain.Action{{Payload: map[string]interface{}{"retry_after_ms": v}}}}
+	}
+
+	for name, v := range map[string]interface{}{
+		"int64":       int64(1500),
+		"float64":     float64(1500),
+		"json.Number": json.Number("1500"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ms, ok := retryAfterMillis(planWith(v))
+			if !ok || ms != 1500 {
+				t.Fatalf("expected (1500, true) for %s, got (%d, %v)", name, ms, ok)
+			}
+		})
+	}
+}
+
+func TestEventsEndpointRateLimitedSetsRetryAfter(t *testing.T) {
+	cfg := config.Default()
+	cfg.Planner.ReplyPolicy = "all"
+	cfg.Gate.CooldownMS = 0
+	cfg.Gate.RateLimit.Scopes = []config.RateLimitScopeConfig{
+		{Name: "per-room", Scope: "tenant:room", Kind: "token_bucket", RatePerSec: 0.01, Burst: 1},
+	}
+	h := testServerWithConfig(t, cfg)
+
+	postEvent := func(eventID string) *httptest.ResponseRecorder {
+		body := map[string]interface{}{
+			"v":         0,
+			"event_id":  eventID,
+			"tenant_id": "t1",
+			"source":    "slack",
+			"room_id":   "r1",
+			"actor": map[string]interface{}{
+				"type": "human",
+				"id":   "u1",
+			},
+			"content": map[string]interface{}{
+				"type": "text",
+				"text": "hello @arbiter",
+			},
+			"ts": time.Now().UTC().Format(time.RFC3339),
+		}
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/v0/events", bytes.NewReader(b))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec1 := postEvent("e-http-2")
+	if rec1.Code != http.StatusOK {
+		t.Fatalf("first event: status must be 200, got %d", rec1.Code)
+	}
+
+	// Consume the pending generation so the room's Generating lock doesn't
+	// shadow the rate-limit check this test targets.
+	var plan1 map[string]interface{}
+	if err := json.Unmarshal(rec1.Body.Bytes(), &plan1); err != nil {
+		t.Fatalf("decode first response: %v", err)
+	}
+	actions, _ := plan1["actions"].([]interface{})
+	action1, _ := actions[0].(map[string]interface{})
+	genBody := map[string]interface{}{
+		"v":         0,
+		"plan_id":   plan1["plan_id"],
+		"action_id": action1["action_id"],
+		"tenant_id": "t1",
+		"text":      "generated",
+	}
+	gb, _ := json.Marshal(genBody)
+	genReq := httptest.NewRequest(http.MethodPost, "/v0/generations", bytes.NewReader(gb))
+	genRec := httptest.NewRecorder()
+	h.ServeHTTP(genRec, genReq)
+	if genRec.Code != http.StatusOK {
+		t.Fatalf("process generation: status must be 200, got %d", genRec.Code)
+	}
+
+	rec := postEvent("e-http-3")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second event: status must be 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected a Retry-After header on the rate-limited response")
+	}
+}
+
+// readSSEFrame reads one "event:"/"data:" pair off a plan stream,
+// skipping blank lines and heartbeat comments.
+func readSSEFrame(t *testing.T, r *bufio.Reader) (event string, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read sse stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" || strings.HasPrefix(line, ":"):
+			continue
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+			return event, data
+		}
+	}
+}
+
+func TestPlanStreamDeliversPlanAndActionResult(t *testing.T) {
+	cfg := config.Default()
+	cfg.Planner.ReplyPolicy = "all"
+	h := testServerWithConfig(t, cfg)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v0/plans/stream?tenant_id=t1", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status must be 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream content type, got %q", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	body := map[string]interface{}{
+		"v":         0,
+		"event_id":  "e-stream-1",
+		"tenant_id": "t1",
+		"source":    "slack",
+		"room_id":   "r1",
+		"actor": map[string]interface{}{
+			"type": "human",
+			"id":   "u1",
+		},
+		"content": map[string]interface{}{
+			"type": "text",
+			"text": "hello there",
+		},
+		"ts": time.Now().UTC().Format(time.RFC3339),
+	}
+	b, _ := json.Marshal(body)
+	evReq := httptest.NewRequest(http.MethodPost, "/v0/events", bytes.NewReader(b))
+	evRec := httptest.NewRecorder()
+	h.ServeHTTP(evRec, evReq)
+	if evRec.Code != http.StatusOK {
+		t.Fatalf("post event: status must be 200, got %d", evRec.Code)
+	}
+
+	event, data := readSSEFrame(t, r)
+	if event != "plan" {
+		t.Fatalf("expected plan event, got %q (data=%s)", event, data)
+	}
+	var plan map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &plan); err != nil {
+		t.Fatalf("decode plan frame: %v", err)
+	}
+	planID, _ := plan["plan_id"].(string)
+	if planID == "" {
+		t.Fatalf("plan frame must carry a plan_id")
+	}
+
+	arBody := map[string]interface{}{
+		"tenant_id":      "t1",
+		"correlation_id": "e-stream-1",
+		"reason_code":    "sent",
+	}
+	ab, _ := json.Marshal(arBody)
+	arReq := httptest.NewRequest(http.MethodPost, "/v0/action-results", bytes.NewReader(ab))
+	arRec := httptest.NewRecorder()
+	h.ServeHTTP(arRec, arReq)
+	if arRec.Code != http.StatusNoContent {
+		t.Fatalf("post action-result: status must be 204, got %d", arRec.Code)
+	}
+
+	event, data = readSSEFrame(t, r)
+	if event != "action_result" {
+		t.Fatalf("expected action_result event, got %q (data=%s)", event, data)
+	}
+	var ar map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &ar); err != nil {
+		t.Fatalf("decode action_result frame: %v", err)
+	}
+	if ar["correlation_id"] != "e-stream-1" {
+		t.Fatalf("expected correlation_id e-stream-1, got %v", ar["correlation_id"])
+	}
+}
+
+func TestPlanStreamRequiresTenantID(t *testing.T) {
+	h := testServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/v0/plans/stream", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status must be 400, got %d", rec.Code)
+	}
+}
@@ -1,11 +1,18 @@
 package planner
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"hash/fnv"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/viasnake/arbiter/internal/config"
 	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/store"
 )
 
 type Intent string
@@ -16,21 +23,62 @@ const (
 	IntentMessage Intent = "MESSAGE"
 )
 
-type Engine struct {
+// Metadata carries the provenance behind a Decide call so app.Service can
+// persist it into the resulting ResponsePlan's PolicyDecisions and audit
+// trail: which reason code drove the intent and, for ExternalHTTP, which
+// upstream policy build produced it.
+type Metadata struct {
+	ReasonCode    string
+	PolicyVersion string
+	Confidence    float64
+}
+
+type Provider interface {
+	Decide(ctx context.Context, ev domain.Event) (Intent, Metadata, error)
+}
+
+// New builds the configured Provider: BuiltinRules by default, or
+// ExternalHTTP (falling back to BuiltinRules per cfg.FailMode) when
+// cfg.Mode is external_http. st is used by ExternalHTTP to attach room
+// context to the request it sends; it's unused by BuiltinRules.
+func New(cfg config.PlannerConfig, st store.Store) Provider {
+	builtin := NewBuiltinRules(cfg)
+	if cfg.Mode != "external_http" {
+		return builtin
+	}
+	return &ExternalHTTP{
+		endpoint: cfg.Endpoint,
+		timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+		failMode: cfg.FailMode,
+		fallback: builtin,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMS) * time.Millisecond},
+		store:    st,
+	}
+}
+
+// BuiltinRules is the hardcoded reply_policy ladder: reply_only,
+// mention_first, probabilistic, or all, keyed off an "@arbiter" mention and
+// a deterministic per-event-id coin flip.
+type BuiltinRules struct {
 	cfg config.PlannerConfig
 }
 
-func New(cfg config.PlannerConfig) *Engine {
-	return &Engine{cfg: cfg}
+func NewBuiltinRules(cfg config.PlannerConfig) *BuiltinRules {
+	return &BuiltinRules{cfg: cfg}
 }
 
-func (e *Engine) Decide(ev domain.Event) Intent {
+func (b *BuiltinRules) Decide(_ context.Context, ev domain.Event) (Intent, Metadata, error) {
+	intent := b.decide(ev)
+	return intent, Metadata{ReasonCode: string(intent), PolicyVersion: "builtin-v0"}, nil
+}
+
+func (b *BuiltinRules) decide(ev domain.Event) Intent {
 	if ev.Content.ReplyTo != nil && strings.TrimSpace(*ev.Content.ReplyTo) != "" {
 		return IntentReply
 	}
 
 	mentioned := isMentioned(ev.Content.Text)
-	switch e.cfg.ReplyPolicy {
+	switch b.cfg.ReplyPolicy {
 	case "all":
 		return IntentMessage
 	case "reply_only":
@@ -42,12 +90,12 @@ func (e *Engine) Decide(ev domain.Event) Intent {
 		if mentioned {
 			return IntentReply
 		}
-		if seededProbability(ev.EventID) < e.cfg.ReplyProbability {
+		if seededProbability(ev.EventID) < b.cfg.ReplyProbability {
 			return IntentMessage
 		}
 		return IntentIgnore
 	case "probabilistic":
-		if seededProbability(ev.EventID) < e.cfg.ReplyProbability {
+		if seededProbability(ev.EventID) < b.cfg.ReplyProbability {
 			return IntentMessage
 		}
 		return IntentIgnore
@@ -67,3 +115,128 @@ func isMentioned(text string) bool {
 	lower := strings.ToLower(text)
 	return strings.Contains(lower, "@arbiter")
 }
+
+// ExternalHTTP delegates Decide to an external classifier/policy service,
+// mirroring authz.ExternalHTTP's shape: POST an event envelope, interpret an
+// {intent, confidence, reason_code, policy_version} response, and apply
+// FailMode if the call or response is unusable.
+type ExternalHTTP struct {
+	endpoint string
+	timeout  time.Duration
+	failMode string
+	fallback Provider
+	client   *http.Client
+
+	// store supplies RoomContext. It may be nil (e.g. in tests that
+	// construct an ExternalHTTP directly), in which case RoomContext is
+	// omitted from the request rather than sent as a zero value, which
+	// would falsely read as "an empty room."
+	store store.Store
+}
+
+// RoomContext is store.RoomState as seen by the classifier: whether a
+// generation is already in flight for the room and how deep its pending
+// queue is. store.Store has no record of message history, so requestPayload
+// does not claim to send any -- only the room context store can actually
+// answer.
+type RoomContext struct {
+	Generating       bool `json:"generating"`
+	PendingQueueSize int  `json:"pending_queue_size"`
+}
+
+type requestPayload struct {
+	V             int                 `json:"v"`
+	TenantID      string              `json:"tenant_id"`
+	CorrelationID string              `json:"correlation_id"`
+	RoomID        string              `json:"room_id"`
+	Source        string              `json:"source"`
+	Actor         domain.Actor        `json:"actor"`
+	Content       domain.EventContent `json:"content"`
+	RoomContext   *RoomContext        `json:"room_context,omitempty"`
+}
+
+type responsePayload struct {
+	V             int     `json:"v"`
+	Intent        string  `json:"intent"`
+	Confidence    float64 `json:"confidence"`
+	ReasonCode    string  `json:"reason_code"`
+	PolicyVersion string  `json:"policy_version"`
+}
+
+func (e *ExternalHTTP) Decide(ctx context.Context, ev domain.Event) (Intent, Metadata, error) {
+	reqBody := requestPayload{
+		V:             domain.ContractVersion,
+		TenantID:      ev.TenantID,
+		CorrelationID: ev.EventID,
+		RoomID:        ev.RoomID,
+		Source:        ev.Source,
+		Actor:         ev.Actor,
+		Content:       ev.Content,
+	}
+	if e.store != nil {
+		room := e.store.GetRoomState(ev.TenantID, ev.RoomID)
+		reqBody.RoomContext = &RoomContext{Generating: room.Generating, PendingQueueSize: room.PendingQueueSize}
+	}
+
+	b, err := json.Marshal(reqBody)
+	if err != nil {
+		return e.applyFailureMode(ctx, ev, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return e.applyFailureMode(ctx, ev, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return e.applyFailureMode(ctx, ev, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return e.applyFailureMode(ctx, ev, fmt.Errorf("external planner returned status %d", resp.StatusCode))
+	}
+
+	var out responsePayload
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return e.applyFailureMode(ctx, ev, err)
+	}
+
+	intent := Intent(out.Intent)
+	switch intent {
+	case IntentIgnore, IntentReply, IntentMessage:
+	default:
+		return e.applyFailureMode(ctx, ev, fmt.Errorf("external planner returned unknown intent %q", out.Intent))
+	}
+
+	reason := out.ReasonCode
+	if reason == "" {
+		reason = "planner_external_" + strings.ToLower(string(intent))
+	}
+	return intent, Metadata{ReasonCode: reason, PolicyVersion: out.PolicyVersion, Confidence: out.Confidence}, nil
+}
+
+// applyFailureMode never returns an error for "ignore" or "builtin" -- those
+// are by definition a recovered outcome. Only "error" propagates the cause
+// so app.Service.ProcessEvent fails the request instead of silently guessing
+// an intent.
+func (e *ExternalHTTP) applyFailureMode(ctx context.Context, ev domain.Event, cause error) (Intent, Metadata, error) {
+	switch e.failMode {
+	case "ignore":
+		return IntentIgnore, Metadata{ReasonCode: "planner_error_ignore"}, nil
+	case "builtin":
+		intent, meta, _ := e.fallback.Decide(ctx, ev)
+		meta.ReasonCode = "planner_error_fallback_builtin"
+		return intent, meta, nil
+	case "error":
+		fallthrough
+	default:
+		return "", Metadata{}, fmt.Errorf("external planner: %w", cause)
+	}
+}
+
+func (e *ExternalHTTP) String() string {
+	return fmt.Sprintf("ExternalHTTP(endpoint=%s, timeout=%s)", e.endpoint, e.timeout)
+}
@@ -1,28 +1,134 @@
 package planner
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/viasnake/arbiter/internal/config"
 	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/store"
 )
 
 func TestDeterministicByEventID(t *testing.T) {
-	eng := New(config.PlannerConfig{ReplyPolicy: "probabilistic", ReplyProbability: 0.5})
+	eng := New(config.PlannerConfig{ReplyPolicy: "probabilistic", ReplyProbability: 0.5}, nil)
 
 	ev := domain.Event{EventID: "same-id", Content: domain.EventContent{Type: "text"}}
-	a := eng.Decide(ev)
-	b := eng.Decide(ev)
+	a, _, err := eng.Decide(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	b, _, err := eng.Decide(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
 	if a != b {
 		t.Fatalf("planner decision must be deterministic for same event_id")
 	}
 }
 
 func TestMentionFirstPrefersReply(t *testing.T) {
-	eng := New(config.PlannerConfig{ReplyPolicy: "mention_first", ReplyProbability: 0.0})
+	eng := New(config.PlannerConfig{ReplyPolicy: "mention_first", ReplyProbability: 0.0}, nil)
 
 	ev := domain.Event{EventID: "e1", Content: domain.EventContent{Type: "text", Text: "hello @arbiter"}}
-	if got := eng.Decide(ev); got != IntentReply {
+	got, meta, err := eng.Decide(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if got != IntentReply {
 		t.Fatalf("expected REPLY, got %s", got)
 	}
+	if meta.PolicyVersion != "builtin-v0" {
+		t.Fatalf("expected builtin-v0 policy version, got %q", meta.PolicyVersion)
+	}
+}
+
+func TestExternalHTTPDecidesFromResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"v":0,"intent":"REPLY","confidence":0.9,"reason_code":"classifier_match","policy_version":"p1"}`))
+	}))
+	defer srv.Close()
+
+	p := New(config.PlannerConfig{Mode: "external_http", Endpoint: srv.URL, TimeoutMS: 200, FailMode: "error"}, nil)
+	intent, meta, err := p.Decide(context.Background(), domain.Event{TenantID: "t1", EventID: "e1", RoomID: "r1"})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if intent != IntentReply {
+		t.Fatalf("expected REPLY, got %s", intent)
+	}
+	if meta.ReasonCode != "classifier_match" || meta.PolicyVersion != "p1" {
+		t.Fatalf("metadata mismatch: got %+v", meta)
+	}
+}
+
+func TestExternalHTTPFailModeBuiltinFallsBack(t *testing.T) {
+	p := New(config.PlannerConfig{
+		Mode: "external_http", Endpoint: "http://127.0.0.1:1", TimeoutMS: 10, FailMode: "builtin",
+		ReplyPolicy: "all",
+	}, nil)
+	intent, meta, err := p.Decide(context.Background(), domain.Event{EventID: "e1", Content: domain.EventContent{Type: "text"}})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if intent != IntentMessage {
+		t.Fatalf("expected fallback builtin to produce MESSAGE under reply_policy=all, got %s", intent)
+	}
+	if meta.ReasonCode != "planner_error_fallback_builtin" {
+		t.Fatalf("expected fallback reason code, got %q", meta.ReasonCode)
+	}
+}
+
+func TestExternalHTTPFailModeErrorPropagates(t *testing.T) {
+	p := New(config.PlannerConfig{Mode: "external_http", Endpoint: "http://127.0.0.1:1", TimeoutMS: 10, FailMode: "error"}, nil)
+	if _, _, err := p.Decide(context.Background(), domain.Event{}); err == nil {
+		t.Fatalf("expected an error with fail_mode=error")
+	}
+}
+
+func TestExternalHTTPSendsRoomContextFromStore(t *testing.T) {
+	st := store.NewMemoryStore()
+	st.PutPendingGeneration(store.PendingGeneration{TenantID: "t1", RoomID: "r1", PlanID: "p1", ActionID: "a1", Kind: "message"})
+
+	var got requestPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"v":0,"intent":"IGNORE","confidence":0,"reason_code":"ok","policy_version":"p1"}`))
+	}))
+	defer srv.Close()
+
+	p := New(config.PlannerConfig{Mode: "external_http", Endpoint: srv.URL, TimeoutMS: 200, FailMode: "error"}, st)
+	if _, _, err := p.Decide(context.Background(), domain.Event{TenantID: "t1", EventID: "e1", RoomID: "r1"}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	if got.RoomContext == nil {
+		t.Fatal("expected the request to include room_context when a store is configured")
+	}
+	if !got.RoomContext.Generating || got.RoomContext.PendingQueueSize != 1 {
+		t.Fatalf("expected room_context to reflect the pending generation, got %+v", got.RoomContext)
+	}
+}
+
+func TestExternalHTTPOmitsRoomContextWithoutStore(t *testing.T) {
+	var got requestPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"v":0,"intent":"IGNORE","confidence":0,"reason_code":"ok","policy_version":"p1"}`))
+	}))
+	defer srv.Close()
+
+	p := New(config.PlannerConfig{Mode: "external_http", Endpoint: srv.URL, TimeoutMS: 200, FailMode: "error"}, nil)
+	if _, _, err := p.Decide(context.Background(), domain.Event{TenantID: "t1", EventID: "e1", RoomID: "r1"}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	if got.RoomContext != nil {
+		t.Fatalf("expected room_context to be omitted with no store configured, got %+v", got.RoomContext)
+	}
 }
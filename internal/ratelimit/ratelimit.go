@@ -0,0 +1,85 @@
+// Package ratelimit evaluates the scoped rate limits configured under
+// gate.rate_limit against the token-bucket and sliding-window primitives
+// store.Store exposes, so limits are enforced consistently whether the
+// store is in-process (MemoryStore) or shared across replicas (RedisStore,
+// PostgresStore).
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+// Result is the outcome of evaluating every configured scope for one event.
+type Result struct {
+	Allowed    bool
+	ReasonCode string
+	RetryAfter time.Duration
+}
+
+// Limiter checks a list of config.RateLimitScopeConfig in order, denying on
+// the first scope that's over budget. A nil *Limiter always allows, so
+// callers don't need a nil check when no scopes are configured.
+type Limiter struct {
+	store  store.Store
+	scopes []config.RateLimitScopeConfig
+}
+
+func New(st store.Store, scopes []config.RateLimitScopeConfig) *Limiter {
+	if len(scopes) == 0 {
+		return nil
+	}
+	return &Limiter{store: st, scopes: scopes}
+}
+
+// Evaluate checks every scope for the given tenant/room/actor at now. A
+// "tenant:room" or "tenant:actor" scope is skipped when the corresponding
+// ID is empty, since its bucket key wouldn't identify anything.
+func (l *Limiter) Evaluate(tenantID, roomID, actorID string, now time.Time) Result {
+	if l == nil {
+		return Result{Allowed: true}
+	}
+
+	for _, sc := range l.scopes {
+		key, ok := scopeKey(sc.Scope, tenantID, roomID, actorID)
+		if !ok {
+			continue
+		}
+		bucketKey := sc.Name + ":" + key
+
+		switch sc.Kind {
+		case "token_bucket":
+			allowed, retryAfter := l.store.TokenBucketTake(bucketKey, sc.RatePerSec, sc.Burst, 1, now)
+			if !allowed {
+				return Result{Allowed: false, ReasonCode: "rate_limited:" + sc.Scope, RetryAfter: retryAfter}
+			}
+		case "sliding_window":
+			window := time.Duration(sc.WindowMS) * time.Millisecond
+			if !l.store.SlidingWindowAllow(bucketKey, window, sc.Limit, now) {
+				return Result{Allowed: false, ReasonCode: "rate_limited:" + sc.Scope, RetryAfter: window}
+			}
+		}
+	}
+	return Result{Allowed: true}
+}
+
+func scopeKey(scope, tenantID, roomID, actorID string) (string, bool) {
+	switch scope {
+	case "tenant":
+		return tenantID, true
+	case "tenant:room":
+		if roomID == "" {
+			return "", false
+		}
+		return tenantID + ":" + roomID, true
+	case "tenant:actor":
+		if actorID == "" {
+			return "", false
+		}
+		return tenantID + ":" + actorID, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+func TestLimiterNilWhenNoScopesConfigured(t *testing.T) {
+	l := New(store.NewMemoryStore(), nil)
+	if got := l.Evaluate("t1", "r1", "u1", time.Now()); !got.Allowed {
+		t.Fatalf("expected a limiter with no scopes to always allow, got %+v", got)
+	}
+}
+
+func TestLimiterTokenBucketScopeDeniesOverBudget(t *testing.T) {
+	scopes := []config.RateLimitScopeConfig{
+		{Name: "per-room", Scope: "tenant:room", Kind: "token_bucket", RatePerSec: 1, Burst: 1},
+	}
+	l := New(store.NewMemoryStore(), scopes)
+
+	now := time.Now()
+	if got := l.Evaluate("t1", "r1", "u1", now); !got.Allowed {
+		t.Fatalf("expected 1st event within burst to be allowed, got %+v", got)
+	}
+	got := l.Evaluate("t1", "r1", "u1", now)
+	if got.Allowed {
+		t.Fatalf("expected 2nd event to exceed the burst of 1")
+	}
+	if got.ReasonCode != "rate_limited:tenant:room" {
+		t.Fatalf("reason_code mismatch: got %q", got.ReasonCode)
+	}
+	if got.RetryAfter <= 0 {
+		t.Fatalf("expected a positive retry-after, got %s", got.RetryAfter)
+	}
+}
+
+func TestLimiterSkipsScopeMissingItsID(t *testing.T) {
+	scopes := []config.RateLimitScopeConfig{
+		{Name: "per-actor", Scope: "tenant:actor", Kind: "token_bucket", RatePerSec: 1, Burst: 1},
+	}
+	l := New(store.NewMemoryStore(), scopes)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		if got := l.Evaluate("t1", "r1", "", now); !got.Allowed {
+			t.Fatalf("expected events with no actor id to skip the tenant:actor scope, got %+v", got)
+		}
+	}
+}
+
+func TestLimiterSlidingWindowScopeDeniesOverLimit(t *testing.T) {
+	scopes := []config.RateLimitScopeConfig{
+		{Name: "per-tenant", Scope: "tenant", Kind: "sliding_window", WindowMS: 60000, Limit: 2},
+	}
+	l := New(store.NewMemoryStore(), scopes)
+
+	now := time.Now()
+	if got := l.Evaluate("t1", "r1", "u1", now); !got.Allowed {
+		t.Fatalf("expected 1st event to be allowed, got %+v", got)
+	}
+	if got := l.Evaluate("t1", "r1", "u1", now); !got.Allowed {
+		t.Fatalf("expected 2nd event to be allowed, got %+v", got)
+	}
+	if got := l.Evaluate("t1", "r1", "u1", now); got.Allowed {
+		t.Fatalf("expected 3rd event to exceed the sliding-window limit of 2")
+	}
+}
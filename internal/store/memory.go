@@ -22,21 +22,30 @@ type RoomState struct {
 	LastSendAt       time.Time
 }
 
+type tokenBucketState struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
 type MemoryStore struct {
 	mu sync.Mutex
 
-	idempotency map[string]domain.ResponsePlan
-	rooms       map[string]*RoomState
-	pending     map[string]PendingGeneration
-	tenantRate  map[string]map[int64]int
+	idempotency   map[string]domain.ResponsePlan
+	rooms         map[string]*RoomState
+	pending       map[string]PendingGeneration
+	tenantRate    map[string]map[int64]int
+	tokenBuckets  map[string]*tokenBucketState
+	slidingEvents map[string][]time.Time
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		idempotency: make(map[string]domain.ResponsePlan),
-		rooms:       make(map[string]*RoomState),
-		pending:     make(map[string]PendingGeneration),
-		tenantRate:  make(map[string]map[int64]int),
+		idempotency:   make(map[string]domain.ResponsePlan),
+		rooms:         make(map[string]*RoomState),
+		pending:       make(map[string]PendingGeneration),
+		tenantRate:    make(map[string]map[int64]int),
+		tokenBuckets:  make(map[string]*tokenBucketState),
+		slidingEvents: make(map[string][]time.Time),
 	}
 }
 
@@ -150,3 +159,47 @@ func (s *MemoryStore) IncrementTenantRate(tenantID string, minuteBucket int64) {
 		}
 	}
 }
+
+func (s *MemoryStore) TokenBucketTake(key string, ratePerSec float64, burst, cost int, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.tokenBuckets[key]
+	if !ok {
+		b = &tokenBucketState{tokens: float64(burst), lastRefill: now}
+		s.tokenBuckets[key] = b
+	}
+	if elapsed := now.Sub(b.lastRefill).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * ratePerSec
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+		b.lastRefill = now
+	}
+
+	if b.tokens >= float64(cost) {
+		b.tokens -= float64(cost)
+		return true, 0
+	}
+	retryAfter := time.Duration((float64(cost) - b.tokens) / ratePerSec * float64(time.Second))
+	return false, retryAfter
+}
+
+func (s *MemoryStore) SlidingWindowAllow(key string, window time.Duration, limit int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-window)
+	kept := s.slidingEvents[key][:0]
+	for _, ts := range s.slidingEvents[key] {
+		if ts.After(cutoff) {
+			kept = append(kept, ts)
+		}
+	}
+	if len(kept) >= limit {
+		s.slidingEvents[key] = kept
+		return false
+	}
+	s.slidingEvents[key] = append(kept, now)
+	return true
+}
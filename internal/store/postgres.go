@@ -0,0 +1,350 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// PostgresStore is a durable Store backed by Postgres. It exists alongside
+// RedisStore for deployments that want a replica-shared store without
+// running a separate Redis cluster; ConsumePendingGeneration uses
+// SELECT ... FOR UPDATE SKIP LOCKED so two arbiter instances racing to
+// consume the same pending generation never both succeed, and the one that
+// loses the race returns immediately instead of blocking on the lock.
+type PostgresStore struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewPostgresStore opens cfg.DSN, applies any outstanding migrations, and
+// returns a ready-to-use store. idempotencyTTL governs how long idempotency
+// rows are honored before GetIdempotency treats them as absent; a zero
+// value disables expiry.
+func NewPostgresStore(cfg config.PostgresConfig, idempotencyTTL time.Duration) (*PostgresStore, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("postgres.dsn is required")
+	}
+
+	db, err := sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres store: %w", err)
+	}
+	if cfg.MaxOpenConn > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConn)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("connect to postgres: %w", err)
+	}
+	if err := migratePostgres(db); err != nil {
+		return nil, fmt.Errorf("migrate postgres store: %w", err)
+	}
+
+	return &PostgresStore{db: db, ttl: idempotencyTTL}, nil
+}
+
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *PostgresStore) GetIdempotency(tenantID, eventID string) (domain.ResponsePlan, bool) {
+	var planJSON []byte
+	var expiresAt int64
+	err := s.db.QueryRow(
+		`SELECT plan_jsonb, expires_at FROM idempotency WHERE tenant_id = $1 AND event_id = $2`,
+		tenantID, eventID,
+	).Scan(&planJSON, &expiresAt)
+	if err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	if expiresAt > 0 && time.Now().Unix() >= expiresAt {
+		return domain.ResponsePlan{}, false
+	}
+
+	var plan domain.ResponsePlan
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	return plan, true
+}
+
+func (s *PostgresStore) PutIdempotency(tenantID, eventID string, plan domain.ResponsePlan) {
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	var expiresAt int64
+	if s.ttl > 0 {
+		expiresAt = time.Now().Add(s.ttl).Unix()
+	}
+	_, _ = s.db.Exec(
+		`INSERT INTO idempotency (tenant_id, event_id, plan_jsonb, expires_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (tenant_id, event_id) DO UPDATE SET plan_jsonb = excluded.plan_jsonb, expires_at = excluded.expires_at`,
+		tenantID, eventID, b, expiresAt,
+	)
+}
+
+func (s *PostgresStore) GetRoomState(tenantID, roomID string) RoomState {
+	room, err := queryPostgresRoomState(s.db, tenantID, roomID)
+	if err != nil {
+		return RoomState{}
+	}
+	return room
+}
+
+func queryPostgresRoomState(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, tenantID, roomID string) (RoomState, error) {
+	var generating bool
+	var pendingQueueSize int
+	var lastSendAt int64
+	err := q.QueryRow(
+		`SELECT generating, pending_queue_size, last_send_at FROM room_state WHERE tenant_id = $1 AND room_id = $2`,
+		tenantID, roomID,
+	).Scan(&generating, &pendingQueueSize, &lastSendAt)
+	if err == sql.ErrNoRows {
+		return RoomState{}, nil
+	}
+	if err != nil {
+		return RoomState{}, err
+	}
+
+	room := RoomState{Generating: generating, PendingQueueSize: pendingQueueSize}
+	if lastSendAt > 0 {
+		room.LastSendAt = time.Unix(0, lastSendAt).UTC()
+	}
+	return room, nil
+}
+
+func (s *PostgresStore) PutPendingGeneration(p PendingGeneration) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	room, err := queryPostgresRoomStateForUpdate(tx, p.TenantID, p.RoomID)
+	if err != nil {
+		return
+	}
+	room.Generating = true
+	room.PendingQueueSize++
+
+	if err := upsertPostgresRoomState(tx, p.TenantID, p.RoomID, room); err != nil {
+		return
+	}
+
+	var replyTo sql.NullString
+	if p.ReplyTo != nil {
+		replyTo = sql.NullString{String: *p.ReplyTo, Valid: true}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO pending_generations (tenant_id, action_id, room_id, plan_id, kind, reply_to) VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (tenant_id, action_id) DO UPDATE SET room_id = excluded.room_id, plan_id = excluded.plan_id, kind = excluded.kind, reply_to = excluded.reply_to`,
+		p.TenantID, p.ActionID, p.RoomID, p.PlanID, p.Kind, replyTo,
+	); err != nil {
+		return
+	}
+
+	_ = tx.Commit()
+}
+
+// ConsumePendingGeneration claims the pending generation row with
+// FOR UPDATE SKIP LOCKED so a concurrent call for the same tenant+action on
+// another replica doesn't block waiting for this transaction -- it simply
+// observes no rows and returns false, the same outcome as the row never
+// having existed.
+func (s *PostgresStore) ConsumePendingGeneration(tenantID, actionID string, at time.Time) (PendingGeneration, bool) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var p PendingGeneration
+	var replyTo sql.NullString
+	err = tx.QueryRow(
+		`SELECT tenant_id, action_id, room_id, plan_id, kind, reply_to FROM pending_generations
+		 WHERE tenant_id = $1 AND action_id = $2 FOR UPDATE SKIP LOCKED`,
+		tenantID, actionID,
+	).Scan(&p.TenantID, &p.ActionID, &p.RoomID, &p.PlanID, &p.Kind, &replyTo)
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	if replyTo.Valid {
+		p.ReplyTo = &replyTo.String
+	}
+
+	if _, err := tx.Exec(`DELETE FROM pending_generations WHERE tenant_id = $1 AND action_id = $2`, tenantID, actionID); err != nil {
+		return PendingGeneration{}, false
+	}
+
+	room, err := queryPostgresRoomStateForUpdate(tx, tenantID, p.RoomID)
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	if room.PendingQueueSize > 0 {
+		room.PendingQueueSize--
+	}
+	if room.PendingQueueSize == 0 {
+		room.Generating = false
+	}
+	room.LastSendAt = at
+
+	if err := upsertPostgresRoomState(tx, tenantID, p.RoomID, room); err != nil {
+		return PendingGeneration{}, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PendingGeneration{}, false
+	}
+	return p, true
+}
+
+// queryPostgresRoomStateForUpdate locks the room_state row (if it exists)
+// for the duration of the caller's transaction so PutPendingGeneration and
+// ConsumePendingGeneration never interleave their read-modify-write of the
+// same room across replicas.
+func queryPostgresRoomStateForUpdate(tx *sql.Tx, tenantID, roomID string) (RoomState, error) {
+	var generating bool
+	var pendingQueueSize int
+	var lastSendAt int64
+	err := tx.QueryRow(
+		`SELECT generating, pending_queue_size, last_send_at FROM room_state WHERE tenant_id = $1 AND room_id = $2 FOR UPDATE`,
+		tenantID, roomID,
+	).Scan(&generating, &pendingQueueSize, &lastSendAt)
+	if err == sql.ErrNoRows {
+		return RoomState{}, nil
+	}
+	if err != nil {
+		return RoomState{}, err
+	}
+
+	room := RoomState{Generating: generating, PendingQueueSize: pendingQueueSize}
+	if lastSendAt > 0 {
+		room.LastSendAt = time.Unix(0, lastSendAt).UTC()
+	}
+	return room, nil
+}
+
+func upsertPostgresRoomState(tx *sql.Tx, tenantID, roomID string, room RoomState) error {
+	var lastSendAt int64
+	if !room.LastSendAt.IsZero() {
+		lastSendAt = room.LastSendAt.UnixNano()
+	}
+	_, err := tx.Exec(
+		`INSERT INTO room_state (tenant_id, room_id, generating, pending_queue_size, last_send_at) VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (tenant_id, room_id) DO UPDATE SET generating = excluded.generating, pending_queue_size = excluded.pending_queue_size, last_send_at = excluded.last_send_at`,
+		tenantID, roomID, room.Generating, room.PendingQueueSize, lastSendAt,
+	)
+	return err
+}
+
+func (s *PostgresStore) TenantRateCount(tenantID string, minuteBucket int64) int {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT count FROM tenant_rate WHERE tenant_id = $1 AND minute_bucket = $2`,
+		tenantID, minuteBucket,
+	).Scan(&count)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+func (s *PostgresStore) IncrementTenantRate(tenantID string, minuteBucket int64) {
+	_, _ = s.db.Exec(
+		`INSERT INTO tenant_rate (tenant_id, minute_bucket, count) VALUES ($1, $2, 1)
+		 ON CONFLICT (tenant_id, minute_bucket) DO UPDATE SET count = tenant_rate.count + 1`,
+		tenantID, minuteBucket,
+	)
+	// best-effort cleanup of old buckets, mirroring MemoryStore's retention window
+	_, _ = s.db.Exec(`DELETE FROM tenant_rate WHERE tenant_id = $1 AND minute_bucket < $2`, tenantID, minuteBucket-5)
+}
+
+// TokenBucketTake mirrors SQLiteStore's implementation but takes FOR
+// UPDATE on the bucket row so two replicas racing on the same key refill
+// and debit serially rather than both reading the same stale token count.
+func (s *PostgresStore) TokenBucketTake(key string, ratePerSec float64, burst, cost int, now time.Time) (bool, time.Duration) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return true, 0
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var tokens float64
+	var lastRefillNS int64
+	err = tx.QueryRow(`SELECT tokens, last_refill FROM token_buckets WHERE key = $1 FOR UPDATE`, key).Scan(&tokens, &lastRefillNS)
+	switch {
+	case err == sql.ErrNoRows:
+		tokens, lastRefillNS = float64(burst), now.UnixNano()
+	case err != nil:
+		return true, 0
+	}
+
+	lastRefill := time.Unix(0, lastRefillNS)
+	if elapsed := now.Sub(lastRefill).Seconds(); elapsed > 0 {
+		tokens += elapsed * ratePerSec
+		if tokens > float64(burst) {
+			tokens = float64(burst)
+		}
+		lastRefill = now
+	}
+
+	var retryAfter time.Duration
+	allowed := tokens >= float64(cost)
+	if allowed {
+		tokens -= float64(cost)
+	} else {
+		retryAfter = time.Duration((float64(cost) - tokens) / ratePerSec * float64(time.Second))
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO token_buckets (key, tokens, last_refill) VALUES ($1, $2, $3)
+		 ON CONFLICT (key) DO UPDATE SET tokens = excluded.tokens, last_refill = excluded.last_refill`,
+		key, tokens, lastRefill.UnixNano(),
+	); err != nil {
+		return true, 0
+	}
+	if err := tx.Commit(); err != nil {
+		return true, 0
+	}
+	return allowed, retryAfter
+}
+
+func (s *PostgresStore) SlidingWindowAllow(key string, window time.Duration, limit int, now time.Time) bool {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return true
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	cutoff := now.Add(-window).UnixNano()
+	if _, err := tx.Exec(`DELETE FROM sliding_window_events WHERE key = $1 AND ts < $2`, key, cutoff); err != nil {
+		return true
+	}
+
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sliding_window_events WHERE key = $1`, key).Scan(&count); err != nil {
+		return true
+	}
+	if count >= limit {
+		_ = tx.Commit()
+		return false
+	}
+
+	if _, err := tx.Exec(`INSERT INTO sliding_window_events (key, ts) VALUES ($1, $2)`, key, now.UnixNano()); err != nil {
+		return true
+	}
+	if err := tx.Commit(); err != nil {
+		return true
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/app"
+	"github.com/viasnake/arbiter/internal/authz"
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/planner"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+// TestPostgresStoreSoakTwoReplicasShareGateState mirrors
+// TestRedisStoreSoakTwoReplicasShareGateState: two *app.Service instances,
+// each with its own PostgresStore pointed at the same database, must agree
+// on which one holds a room's Generating lease.
+//
+// Requires a real Postgres; set ARBITER_TEST_POSTGRES_DSN to run it.
+func TestPostgresStoreSoakTwoReplicasShareGateState(t *testing.T) {
+	dsn := os.Getenv("ARBITER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("set ARBITER_TEST_POSTGRES_DSN to run the Postgres store soak test")
+	}
+
+	cfg := config.Default()
+	cfg.Gate.CooldownMS = 0
+	cfg.Planner.ReplyPolicy = "all"
+	pgCfg := config.PostgresConfig{DSN: dsn}
+
+	st1, err := store.NewPostgresStore(pgCfg, time.Hour)
+	if err != nil {
+		t.Fatalf("open postgres (replica 1): %v", err)
+	}
+	defer st1.Close()
+	st2, err := store.NewPostgresStore(pgCfg, time.Hour)
+	if err != nil {
+		t.Fatalf("open postgres (replica 2): %v", err)
+	}
+	defer st2.Close()
+
+	svc1 := app.NewService(cfg, st1, authz.BuiltinAllowAll{}, planner.New(cfg.Planner, st1), discardAudit{})
+	svc2 := app.NewService(cfg, st2, authz.BuiltinAllowAll{}, planner.New(cfg.Planner, st2), discardAudit{})
+
+	roomID := "pg-soak-room"
+	ts := time.Now().UTC().Format(time.RFC3339)
+
+	ev1 := domain.Event{
+		V: domain.ContractVersion, EventID: "pg-soak-ev1", TenantID: "pg-soak-tenant", Source: "slack", RoomID: roomID,
+		Actor:   domain.Actor{Type: "human", ID: "u1"},
+		Content: domain.EventContent{Type: "text", Text: "hello @arbiter"},
+		TS:      ts,
+	}
+	plan1, err := svc1.ProcessEvent(context.Background(), ev1)
+	if err != nil {
+		t.Fatalf("svc1.ProcessEvent: %v", err)
+	}
+	if plan1.Actions[0].Type != domain.ActionRequestGeneration {
+		t.Fatalf("expected replica 1 to kick off a generation, got %+v", plan1.Actions[0])
+	}
+
+	ev2 := ev1
+	ev2.EventID = "pg-soak-ev2"
+	plan2, err := svc2.ProcessEvent(context.Background(), ev2)
+	if err != nil {
+		t.Fatalf("svc2.ProcessEvent: %v", err)
+	}
+	if got := plan2.Actions[0]; got.Type != domain.ActionDoNothing || got.Payload["reason_code"] != "gate_generating_lock" {
+		t.Fatalf("expected replica 2 to observe replica 1's generating lock, got %+v", got)
+	}
+}
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// RedisStore implements Store against Redis so multiple arbiter replicas
+// behind a load balancer share idempotency, gate, and rate-limit state
+// instead of each replica holding its own in-process copy like MemoryStore
+// does. The Generating lock is a separate key with a lease (SET NX PX) so a
+// crashed replica can't pin a room's gate open forever, and
+// ConsumePendingGeneration runs as a Lua script so
+// PendingQueueSize/Generating/LastSendAt move together atomically.
+type RedisStore struct {
+	client         redis.UniversalClient
+	idempotencyTTL time.Duration
+	leaseMS        int64
+
+	putScript     *redis.Script
+	consumeScript *redis.Script
+
+	tokenBucketScript   *redis.Script
+	slidingWindowScript *redis.Script
+}
+
+const (
+	// rateBucketTTL reclaims a tenant-minute counter key well after the gate
+	// could ever consult it again.
+	rateBucketTTL = 2 * time.Minute
+	// pendingTTLFactor bounds how long an orphaned pending-generation record
+	// survives (e.g. the generation worker that should have consumed it
+	// crashed) before Redis reclaims it on its own.
+	pendingTTLFactor = 10
+	defaultLeaseMS   = 30000
+	// tokenBucketTTLSeconds reclaims an idle token bucket well after its
+	// tokens would have fully refilled, so quiet scopes don't leak keys.
+	tokenBucketTTLSeconds = 3600
+)
+
+// tokenBucketScript refills the bucket for elapsed time since its last
+// touch (capped at burst), then debits cost tokens if enough are
+// available. Refill and debit happen in one round trip so concurrent
+// callers never read the same stale token count.
+//
+// KEYS[1] = bucket key
+// ARGV[1] = rate per second, ARGV[2] = burst, ARGV[3] = cost,
+// ARGV[4] = now (unix seconds, float), ARGV[5] = key TTL seconds
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local ttl = tonumber(ARGV[5])
+
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+local last = tonumber(redis.call('HGET', KEYS[1], 'last_refill'))
+if tokens == nil then
+  tokens = burst
+  last = now
+end
+
+local elapsed = now - last
+if elapsed > 0 then
+  tokens = math.min(burst, tokens + elapsed * rate)
+  last = now
+end
+
+local allowed = 0
+local retry_after = 0
+if tokens >= cost then
+  tokens = tokens - cost
+  allowed = 1
+else
+  retry_after = (cost - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last_refill', tostring(last))
+redis.call('EXPIRE', KEYS[1], ttl)
+
+return {allowed, tostring(retry_after)}
+`
+
+// slidingWindowScript evicts events older than the window, then admits the
+// new one only if fewer than limit remain -- eviction, count, and insert
+// all happen atomically so two concurrent callers can't both observe room
+// under the limit and both be admitted.
+//
+// KEYS[1] = window zset key
+// ARGV[1] = cutoff (exclusive lower bound, unix nanos), ARGV[2] = limit,
+// ARGV[3] = now (unix nanos, used as score and member), ARGV[4] = key TTL seconds
+const slidingWindowScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+local count = redis.call('ZCARD', KEYS[1])
+if count >= tonumber(ARGV[2]) then
+  return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[3])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`
+
+// putPendingGenerationScript acquires the room's Generating lease, bumps its
+// queue size, and records the pending generation in one round trip.
+//
+// KEYS[1] = room lock key, KEYS[2] = room hash key, KEYS[3] = pending key
+// ARGV[1] = lease ms, ARGV[2] = pending generation JSON, ARGV[3] = pending TTL seconds
+const putPendingGenerationScript = `
+redis.call('SET', KEYS[1], '1', 'NX', 'PX', ARGV[1])
+redis.call('HINCRBY', KEYS[2], 'queue_size', 1)
+redis.call('SET', KEYS[3], ARGV[2], 'EX', ARGV[3])
+return 1
+`
+
+// consumePendingGenerationScript pops the pending generation (if any),
+// decrements the room's queue size, releases the Generating lock once the
+// queue drains, and stamps last_send_at -- all atomically, so a concurrent
+// gate.Evaluate call never observes a half-updated room.
+//
+// KEYS[1] = pending key
+// ARGV[1] = room hash key prefix ("arbiter:room:"), ARGV[2] = now unix nano
+const consumePendingGenerationScript = `
+local pending = redis.call('GET', KEYS[1])
+if not pending then
+  return false
+end
+redis.call('DEL', KEYS[1])
+
+local p = cjson.decode(pending)
+local roomHashKey = ARGV[1] .. p.TenantID .. ':' .. p.RoomID
+local lockKey = roomHashKey .. ':lock'
+
+local size = redis.call('HINCRBY', roomHashKey, 'queue_size', -1)
+if size <= 0 then
+  redis.call('HSET', roomHashKey, 'queue_size', 0)
+  redis.call('DEL', lockKey)
+end
+redis.call('HSET', roomHashKey, 'last_send_at_ns', ARGV[2])
+
+return pending
+`
+
+// NewRedisStore dials cfg.Addrs (standalone, sentinel, or cluster -- whatever
+// redis.NewUniversalClient infers from the address list) and returns a
+// ready-to-use store once the connection is confirmed with PING.
+func NewRedisStore(cfg config.RedisConfig, idempotencyTTL time.Duration) (*RedisStore, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, errors.New("redis.addrs is required")
+	}
+
+	leaseMS := int64(cfg.LeaseMS)
+	if leaseMS <= 0 {
+		leaseMS = defaultLeaseMS
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:    cfg.Addrs,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewUniversalClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+
+	return &RedisStore{
+		client:              client,
+		idempotencyTTL:      idempotencyTTL,
+		leaseMS:             leaseMS,
+		putScript:           redis.NewScript(putPendingGenerationScript),
+		consumeScript:       redis.NewScript(consumePendingGenerationScript),
+		tokenBucketScript:   redis.NewScript(tokenBucketScript),
+		slidingWindowScript: redis.NewScript(slidingWindowScript),
+	}, nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func idemKey(tenantID, eventID string) string {
+	return "arbiter:idem:" + tenantID + ":" + eventID
+}
+
+func roomHashKeyPrefix() string {
+	return "arbiter:room:"
+}
+
+func roomHashKey(tenantID, roomID string) string {
+	return roomHashKeyPrefix() + tenantID + ":" + roomID
+}
+
+func roomLockKey(tenantID, roomID string) string {
+	return roomHashKey(tenantID, roomID) + ":lock"
+}
+
+func pendingGenKey(tenantID, actionID string) string {
+	return "arbiter:pending:" + tenantID + ":" + actionID
+}
+
+func tenantRateKey(tenantID string, minuteBucket int64) string {
+	return fmt.Sprintf("arbiter:rate:%s:%d", tenantID, minuteBucket)
+}
+
+func tokenBucketKey(key string) string {
+	return "arbiter:ratelimit:bucket:" + key
+}
+
+func slidingWindowKey(key string) string {
+	return "arbiter:ratelimit:window:" + key
+}
+
+func (s *RedisStore) GetIdempotency(tenantID, eventID string) (domain.ResponsePlan, bool) {
+	b, err := s.client.Get(context.Background(), idemKey(tenantID, eventID)).Bytes()
+	if err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	var plan domain.ResponsePlan
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	return plan, true
+}
+
+func (s *RedisStore) PutIdempotency(tenantID, eventID string, plan domain.ResponsePlan) {
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	_ = s.client.SetEx(context.Background(), idemKey(tenantID, eventID), b, s.idempotencyTTL).Err()
+}
+
+func (s *RedisStore) GetRoomState(tenantID, roomID string) RoomState {
+	ctx := context.Background()
+	hKey := roomHashKey(tenantID, roomID)
+
+	pipe := s.client.Pipeline()
+	sizeCmd := pipe.HGet(ctx, hKey, "queue_size")
+	lastSendCmd := pipe.HGet(ctx, hKey, "last_send_at_ns")
+	lockCmd := pipe.Exists(ctx, roomLockKey(tenantID, roomID))
+	_, _ = pipe.Exec(ctx)
+
+	var room RoomState
+	if n, err := sizeCmd.Int(); err == nil {
+		room.PendingQueueSize = n
+	}
+	if ns, err := lastSendCmd.Int64(); err == nil && ns > 0 {
+		room.LastSendAt = time.Unix(0, ns).UTC()
+	}
+	room.Generating = lockCmd.Val() > 0
+	return room
+}
+
+func (s *RedisStore) PutPendingGeneration(p PendingGeneration) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	pendingTTLSeconds := (s.leaseMS * pendingTTLFactor) / 1000
+	if pendingTTLSeconds <= 0 {
+		pendingTTLSeconds = 1
+	}
+
+	keys := []string{roomLockKey(p.TenantID, p.RoomID), roomHashKey(p.TenantID, p.RoomID), pendingGenKey(p.TenantID, p.ActionID)}
+	_ = s.putScript.Run(context.Background(), s.client, keys, s.leaseMS, string(b), pendingTTLSeconds).Err()
+}
+
+func (s *RedisStore) ConsumePendingGeneration(tenantID, actionID string, at time.Time) (PendingGeneration, bool) {
+	keys := []string{pendingGenKey(tenantID, actionID)}
+	raw, err := s.consumeScript.Run(context.Background(), s.client, keys, roomHashKeyPrefix(), at.UnixNano()).Text()
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+
+	var p PendingGeneration
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return PendingGeneration{}, false
+	}
+	return p, true
+}
+
+func (s *RedisStore) TenantRateCount(tenantID string, minuteBucket int64) int {
+	n, err := s.client.Get(context.Background(), tenantRateKey(tenantID, minuteBucket)).Int()
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (s *RedisStore) IncrementTenantRate(tenantID string, minuteBucket int64) {
+	ctx := context.Background()
+	key := tenantRateKey(tenantID, minuteBucket)
+	n, err := s.client.Incr(ctx, key).Result()
+	if err != nil {
+		return
+	}
+	if n == 1 {
+		_ = s.client.Expire(ctx, key, rateBucketTTL).Err()
+	}
+}
+
+func (s *RedisStore) TokenBucketTake(key string, ratePerSec float64, burst, cost int, now time.Time) (bool, time.Duration) {
+	nowSeconds := float64(now.UnixNano()) / float64(time.Second)
+	res, err := s.tokenBucketScript.Run(
+		context.Background(), s.client,
+		[]string{tokenBucketKey(key)},
+		ratePerSec, burst, cost, nowSeconds, tokenBucketTTLSeconds,
+	).Slice()
+	if err != nil || len(res) != 2 {
+		return true, 0
+	}
+
+	allowed, _ := res[0].(int64)
+	retrySeconds, err := strconv.ParseFloat(fmt.Sprint(res[1]), 64)
+	if err != nil {
+		return true, 0
+	}
+	return allowed == 1, time.Duration(retrySeconds * float64(time.Second))
+}
+
+func (s *RedisStore) SlidingWindowAllow(key string, window time.Duration, limit int, now time.Time) bool {
+	cutoff := now.Add(-window).UnixNano()
+	ttl := int64(window.Seconds()) + 1
+	res, err := s.slidingWindowScript.Run(
+		context.Background(), s.client,
+		[]string{slidingWindowKey(key)},
+		cutoff, limit, now.UnixNano(), ttl,
+	).Int64()
+	if err != nil {
+		return true
+	}
+	return res == 1
+}
@@ -0,0 +1,82 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/app"
+	"github.com/viasnake/arbiter/internal/audit"
+	"github.com/viasnake/arbiter/internal/authz"
+	"github.com/viasnake/arbiter/internal/config"
+	"github.com/viasnake/arbiter/internal/domain"
+	"github.com/viasnake/arbiter/internal/planner"
+	"github.com/viasnake/arbiter/internal/store"
+)
+
+type discardAudit struct{}
+
+func (discardAudit) Append(audit.Record) error { return nil }
+func (discardAudit) Close() error              { return nil }
+
+// TestRedisStoreSoakTwoReplicasShareGateState starts two *app.Service
+// instances, each with its own RedisStore pointed at the same Redis, and
+// checks that the Generating lease one replica takes is visible to the
+// other: a concurrent event for the same room must be rejected with
+// gate_generating_lock rather than racing past it the way two MemoryStores
+// in two processes would.
+//
+// Requires a real Redis; set ARBITER_TEST_REDIS_ADDR to run it.
+func TestRedisStoreSoakTwoReplicasShareGateState(t *testing.T) {
+	addr := os.Getenv("ARBITER_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("set ARBITER_TEST_REDIS_ADDR to run the Redis store soak test")
+	}
+
+	cfg := config.Default()
+	cfg.Gate.CooldownMS = 0
+	cfg.Planner.ReplyPolicy = "all"
+	redisCfg := config.RedisConfig{Addrs: []string{addr}, LeaseMS: 30000}
+
+	st1, err := store.NewRedisStore(redisCfg, time.Hour)
+	if err != nil {
+		t.Fatalf("dial redis (replica 1): %v", err)
+	}
+	defer st1.Close()
+	st2, err := store.NewRedisStore(redisCfg, time.Hour)
+	if err != nil {
+		t.Fatalf("dial redis (replica 2): %v", err)
+	}
+	defer st2.Close()
+
+	svc1 := app.NewService(cfg, st1, authz.BuiltinAllowAll{}, planner.New(cfg.Planner, st1), discardAudit{})
+	svc2 := app.NewService(cfg, st2, authz.BuiltinAllowAll{}, planner.New(cfg.Planner, st2), discardAudit{})
+
+	roomID := "soak-room"
+	ts := time.Now().UTC().Format(time.RFC3339)
+
+	ev1 := domain.Event{
+		V: domain.ContractVersion, EventID: "soak-ev1", TenantID: "soak-tenant", Source: "slack", RoomID: roomID,
+		Actor:   domain.Actor{Type: "human", ID: "u1"},
+		Content: domain.EventContent{Type: "text", Text: "hello @arbiter"},
+		TS:      ts,
+	}
+	plan1, err := svc1.ProcessEvent(context.Background(), ev1)
+	if err != nil {
+		t.Fatalf("svc1.ProcessEvent: %v", err)
+	}
+	if plan1.Actions[0].Type != domain.ActionRequestGeneration {
+		t.Fatalf("expected replica 1 to kick off a generation, got %+v", plan1.Actions[0])
+	}
+
+	ev2 := ev1
+	ev2.EventID = "soak-ev2"
+	plan2, err := svc2.ProcessEvent(context.Background(), ev2)
+	if err != nil {
+		t.Fatalf("svc2.ProcessEvent: %v", err)
+	}
+	if got := plan2.Actions[0]; got.Type != domain.ActionDoNothing || got.Payload["reason_code"] != "gate_generating_lock" {
+		t.Fatalf("expected replica 2 to observe replica 1's generating lock, got %+v", got)
+	}
+}
@@ -0,0 +1,326 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// SQLiteStore is a durable Store backed by a single SQLite database file. It
+// applies its schema via the embedded migrations in migrate.go and uses
+// transactions around the pending-generation state transition so
+// Generating/PendingQueueSize cannot diverge if the process crashes
+// mid-request.
+type SQLiteStore struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewSQLiteStore opens (or creates) the database at path, applies any
+// outstanding migrations, and returns a ready-to-use store. idempotencyTTL
+// governs how long idempotency rows are honored before GetIdempotency treats
+// them as absent; a zero value disables expiry.
+func NewSQLiteStore(path string, idempotencyTTL time.Duration) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite store: %w", err)
+	}
+	// The pending-generation transaction and room-state updates rely on
+	// serialized writes; SQLite only supports one writer at a time anyway.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`PRAGMA journal_mode=WAL`); err != nil {
+		return nil, fmt.Errorf("enable wal: %w", err)
+	}
+	if _, err := db.Exec(`PRAGMA foreign_keys=ON`); err != nil {
+		return nil, fmt.Errorf("enable foreign keys: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("migrate sqlite store: %w", err)
+	}
+
+	return &SQLiteStore{db: db, ttl: idempotencyTTL}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) GetIdempotency(tenantID, eventID string) (domain.ResponsePlan, bool) {
+	var planJSON string
+	var expiresAt int64
+	err := s.db.QueryRow(
+		`SELECT plan_json, expires_at FROM idempotency WHERE tenant_id = ? AND event_id = ?`,
+		tenantID, eventID,
+	).Scan(&planJSON, &expiresAt)
+	if err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	if expiresAt > 0 && time.Now().Unix() >= expiresAt {
+		return domain.ResponsePlan{}, false
+	}
+
+	var plan domain.ResponsePlan
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return domain.ResponsePlan{}, false
+	}
+	return plan, true
+}
+
+func (s *SQLiteStore) PutIdempotency(tenantID, eventID string, plan domain.ResponsePlan) {
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	var expiresAt int64
+	if s.ttl > 0 {
+		expiresAt = time.Now().Add(s.ttl).Unix()
+	}
+	_, _ = s.db.Exec(
+		`INSERT INTO idempotency (tenant_id, event_id, plan_json, expires_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (tenant_id, event_id) DO UPDATE SET plan_json = excluded.plan_json, expires_at = excluded.expires_at`,
+		tenantID, eventID, string(b), expiresAt,
+	)
+}
+
+func (s *SQLiteStore) GetRoomState(tenantID, roomID string) RoomState {
+	room, err := queryRoomState(s.db, tenantID, roomID)
+	if err != nil {
+		return RoomState{}
+	}
+	return room
+}
+
+func queryRoomState(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, tenantID, roomID string) (RoomState, error) {
+	var generating int
+	var pendingQueueSize int
+	var lastSendAt int64
+	err := q.QueryRow(
+		`SELECT generating, pending_queue_size, last_send_at FROM room_state WHERE tenant_id = ? AND room_id = ?`,
+		tenantID, roomID,
+	).Scan(&generating, &pendingQueueSize, &lastSendAt)
+	if err == sql.ErrNoRows {
+		return RoomState{}, nil
+	}
+	if err != nil {
+		return RoomState{}, err
+	}
+
+	room := RoomState{
+		Generating:       generating != 0,
+		PendingQueueSize: pendingQueueSize,
+	}
+	if lastSendAt > 0 {
+		room.LastSendAt = time.Unix(0, lastSendAt).UTC()
+	}
+	return room, nil
+}
+
+func (s *SQLiteStore) PutPendingGeneration(p PendingGeneration) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	room, err := queryRoomState(tx, p.TenantID, p.RoomID)
+	if err != nil {
+		return
+	}
+	room.Generating = true
+	room.PendingQueueSize++
+
+	if err := upsertRoomState(tx, p.TenantID, p.RoomID, room); err != nil {
+		return
+	}
+
+	var replyTo sql.NullString
+	if p.ReplyTo != nil {
+		replyTo = sql.NullString{String: *p.ReplyTo, Valid: true}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO pending_generations (tenant_id, action_id, room_id, plan_id, kind, reply_to) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (tenant_id, action_id) DO UPDATE SET room_id = excluded.room_id, plan_id = excluded.plan_id, kind = excluded.kind, reply_to = excluded.reply_to`,
+		p.TenantID, p.ActionID, p.RoomID, p.PlanID, p.Kind, replyTo,
+	); err != nil {
+		return
+	}
+
+	_ = tx.Commit()
+}
+
+func (s *SQLiteStore) ConsumePendingGeneration(tenantID, actionID string, at time.Time) (PendingGeneration, bool) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var p PendingGeneration
+	var replyTo sql.NullString
+	err = tx.QueryRow(
+		`SELECT tenant_id, action_id, room_id, plan_id, kind, reply_to FROM pending_generations WHERE tenant_id = ? AND action_id = ?`,
+		tenantID, actionID,
+	).Scan(&p.TenantID, &p.ActionID, &p.RoomID, &p.PlanID, &p.Kind, &replyTo)
+	if err == sql.ErrNoRows {
+		return PendingGeneration{}, false
+	}
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	if replyTo.Valid {
+		p.ReplyTo = &replyTo.String
+	}
+
+	if _, err := tx.Exec(`DELETE FROM pending_generations WHERE tenant_id = ? AND action_id = ?`, tenantID, actionID); err != nil {
+		return PendingGeneration{}, false
+	}
+
+	room, err := queryRoomState(tx, tenantID, p.RoomID)
+	if err != nil {
+		return PendingGeneration{}, false
+	}
+	if room.PendingQueueSize > 0 {
+		room.PendingQueueSize--
+	}
+	if room.PendingQueueSize == 0 {
+		room.Generating = false
+	}
+	room.LastSendAt = at
+
+	if err := upsertRoomState(tx, tenantID, p.RoomID, room); err != nil {
+		return PendingGeneration{}, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PendingGeneration{}, false
+	}
+	return p, true
+}
+
+func upsertRoomState(tx *sql.Tx, tenantID, roomID string, room RoomState) error {
+	generating := 0
+	if room.Generating {
+		generating = 1
+	}
+	var lastSendAt int64
+	if !room.LastSendAt.IsZero() {
+		lastSendAt = room.LastSendAt.UnixNano()
+	}
+	_, err := tx.Exec(
+		`INSERT INTO room_state (tenant_id, room_id, generating, pending_queue_size, last_send_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (tenant_id, room_id) DO UPDATE SET generating = excluded.generating, pending_queue_size = excluded.pending_queue_size, last_send_at = excluded.last_send_at`,
+		tenantID, roomID, generating, room.PendingQueueSize, lastSendAt,
+	)
+	return err
+}
+
+func (s *SQLiteStore) TenantRateCount(tenantID string, minuteBucket int64) int {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT count FROM tenant_rate WHERE tenant_id = ? AND minute_bucket = ?`,
+		tenantID, minuteBucket,
+	).Scan(&count)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+func (s *SQLiteStore) IncrementTenantRate(tenantID string, minuteBucket int64) {
+	_, _ = s.db.Exec(
+		`INSERT INTO tenant_rate (tenant_id, minute_bucket, count) VALUES (?, ?, 1)
+		 ON CONFLICT (tenant_id, minute_bucket) DO UPDATE SET count = count + 1`,
+		tenantID, minuteBucket,
+	)
+	// best-effort cleanup of old buckets, mirroring MemoryStore's retention window
+	_, _ = s.db.Exec(`DELETE FROM tenant_rate WHERE tenant_id = ? AND minute_bucket < ?`, tenantID, minuteBucket-5)
+}
+
+// TokenBucketTake refills and (if possible) debits the bucket in one
+// transaction. Any failure along the way fails open -- the same stance
+// TenantRateCount takes on a read error -- rather than denying an event
+// because the store hiccuped.
+func (s *SQLiteStore) TokenBucketTake(key string, ratePerSec float64, burst, cost int, now time.Time) (bool, time.Duration) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return true, 0
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var tokens float64
+	var lastRefillNS int64
+	err = tx.QueryRow(`SELECT tokens, last_refill FROM token_buckets WHERE key = ?`, key).Scan(&tokens, &lastRefillNS)
+	switch {
+	case err == sql.ErrNoRows:
+		tokens, lastRefillNS = float64(burst), now.UnixNano()
+	case err != nil:
+		return true, 0
+	}
+
+	lastRefill := time.Unix(0, lastRefillNS)
+	if elapsed := now.Sub(lastRefill).Seconds(); elapsed > 0 {
+		tokens += elapsed * ratePerSec
+		if tokens > float64(burst) {
+			tokens = float64(burst)
+		}
+		lastRefill = now
+	}
+
+	var retryAfter time.Duration
+	allowed := tokens >= float64(cost)
+	if allowed {
+		tokens -= float64(cost)
+	} else {
+		retryAfter = time.Duration((float64(cost) - tokens) / ratePerSec * float64(time.Second))
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO token_buckets (key, tokens, last_refill) VALUES (?, ?, ?)
+		 ON CONFLICT (key) DO UPDATE SET tokens = excluded.tokens, last_refill = excluded.last_refill`,
+		key, tokens, lastRefill.UnixNano(),
+	); err != nil {
+		return true, 0
+	}
+	if err := tx.Commit(); err != nil {
+		return true, 0
+	}
+	return allowed, retryAfter
+}
+
+func (s *SQLiteStore) SlidingWindowAllow(key string, window time.Duration, limit int, now time.Time) bool {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return true
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	cutoff := now.Add(-window).UnixNano()
+	if _, err := tx.Exec(`DELETE FROM sliding_window_events WHERE key = ? AND ts < ?`, key, cutoff); err != nil {
+		return true
+	}
+
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sliding_window_events WHERE key = ?`, key).Scan(&count); err != nil {
+		return true
+	}
+	if count >= limit {
+		_ = tx.Commit()
+		return false
+	}
+
+	if _, err := tx.Exec(`INSERT INTO sliding_window_events (key, ts) VALUES (?, ?)`, key, now.UnixNano()); err != nil {
+		return true
+	}
+	if err := tx.Commit(); err != nil {
+		return true
+	}
+	return true
+}
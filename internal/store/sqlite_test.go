@@ -0,0 +1,175 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+func openTestSQLiteStore(t *testing.T, ttl time.Duration) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "arbiter.db")
+	st, err := NewSQLiteStore(path, ttl)
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st, path
+}
+
+func TestSQLiteStoreIdempotency(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, time.Hour)
+
+	if _, ok := st.GetIdempotency("t1", "e1"); ok {
+		t.Fatalf("expected miss before put")
+	}
+
+	plan := domain.ResponsePlan{V: domain.ContractVersion, PlanID: "plan_x", TenantID: "t1", RoomID: "r1"}
+	st.PutIdempotency("t1", "e1", plan)
+
+	got, ok := st.GetIdempotency("t1", "e1")
+	if !ok {
+		t.Fatalf("expected hit after put")
+	}
+	if got.PlanID != plan.PlanID {
+		t.Fatalf("plan_id mismatch: got %s want %s", got.PlanID, plan.PlanID)
+	}
+}
+
+func TestSQLiteStoreIdempotencyExpires(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, time.Nanosecond)
+
+	st.PutIdempotency("t1", "e1", domain.ResponsePlan{PlanID: "plan_x"})
+	time.Sleep(time.Millisecond)
+
+	if _, ok := st.GetIdempotency("t1", "e1"); ok {
+		t.Fatalf("expected expired idempotency row to be treated as absent")
+	}
+}
+
+func TestSQLiteStorePendingGenerationLifecycle(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, 0)
+
+	st.PutPendingGeneration(PendingGeneration{TenantID: "t1", RoomID: "r1", PlanID: "plan_x", ActionID: "act_x", Kind: "REPLY"})
+
+	room := st.GetRoomState("t1", "r1")
+	if !room.Generating || room.PendingQueueSize != 1 {
+		t.Fatalf("expected generating lock with queue size 1, got %+v", room)
+	}
+
+	now := time.Now().UTC()
+	p, ok := st.ConsumePendingGeneration("t1", "act_x", now)
+	if !ok {
+		t.Fatalf("expected pending generation to be consumed")
+	}
+	if p.PlanID != "plan_x" {
+		t.Fatalf("plan_id mismatch: got %s", p.PlanID)
+	}
+
+	room = st.GetRoomState("t1", "r1")
+	if room.Generating || room.PendingQueueSize != 0 {
+		t.Fatalf("expected queue drained after consume, got %+v", room)
+	}
+	if !room.LastSendAt.Equal(now) {
+		t.Fatalf("last_send_at mismatch: got %s want %s", room.LastSendAt, now)
+	}
+
+	if _, ok := st.ConsumePendingGeneration("t1", "act_x", now); ok {
+		t.Fatalf("expected second consume of the same action to miss")
+	}
+}
+
+func TestSQLiteStoreTenantRate(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, 0)
+
+	if got := st.TenantRateCount("t1", 100); got != 0 {
+		t.Fatalf("expected 0 before increment, got %d", got)
+	}
+	st.IncrementTenantRate("t1", 100)
+	st.IncrementTenantRate("t1", 100)
+	if got := st.TenantRateCount("t1", 100); got != 2 {
+		t.Fatalf("expected 2 after two increments, got %d", got)
+	}
+}
+
+func TestSQLiteStoreTokenBucketTake(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, 0)
+
+	now := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		if allowed, _ := st.TokenBucketTake("k1", 1, 3, 1, now); !allowed {
+			t.Fatalf("expected take %d within burst to succeed", i)
+		}
+	}
+
+	allowed, retryAfter := st.TokenBucketTake("k1", 1, 3, 1, now)
+	if allowed {
+		t.Fatalf("expected 4th take to exceed the burst of 3")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("expected a positive retry-after, got %s", retryAfter)
+	}
+
+	later := now.Add(2 * time.Second)
+	if allowed, _ := st.TokenBucketTake("k1", 1, 3, 1, later); !allowed {
+		t.Fatalf("expected take to succeed after refill")
+	}
+}
+
+func TestSQLiteStoreSlidingWindowAllow(t *testing.T) {
+	st, _ := openTestSQLiteStore(t, 0)
+
+	now := time.Now().UTC()
+	if !st.SlidingWindowAllow("k1", time.Minute, 2, now) {
+		t.Fatalf("expected 1st event within the window limit to be allowed")
+	}
+	if !st.SlidingWindowAllow("k1", time.Minute, 2, now) {
+		t.Fatalf("expected 2nd event within the window limit to be allowed")
+	}
+	if st.SlidingWindowAllow("k1", time.Minute, 2, now) {
+		t.Fatalf("expected 3rd event within the window limit to be denied")
+	}
+
+	later := now.Add(2 * time.Minute)
+	if !st.SlidingWindowAllow("k1", time.Minute, 2, later) {
+		t.Fatalf("expected event after the window elapsed to be allowed")
+	}
+}
+
+func TestSQLiteStoreRestartAndRecover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arbiter.db")
+
+	st, err := NewSQLiteStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	st.PutIdempotency("t1", "e1", domain.ResponsePlan{PlanID: "plan_x"})
+	st.PutPendingGeneration(PendingGeneration{TenantID: "t1", RoomID: "r1", PlanID: "plan_x", ActionID: "act_x", Kind: "REPLY"})
+	st.IncrementTenantRate("t1", 100)
+	if err := st.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
+
+	reopened, err := NewSQLiteStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("reopen sqlite store: %v", err)
+	}
+	defer reopened.Close()
+
+	if _, ok := reopened.GetIdempotency("t1", "e1"); !ok {
+		t.Fatalf("expected idempotency to survive restart")
+	}
+	room := reopened.GetRoomState("t1", "r1")
+	if !room.Generating || room.PendingQueueSize != 1 {
+		t.Fatalf("expected pending generation lock to survive restart, got %+v", room)
+	}
+	if got := reopened.TenantRateCount("t1", 100); got != 1 {
+		t.Fatalf("expected tenant rate to survive restart, got %d", got)
+	}
+
+	if _, ok := reopened.ConsumePendingGeneration("t1", "act_x", time.Now().UTC()); !ok {
+		t.Fatalf("expected pending generation to be consumable after restart")
+	}
+}
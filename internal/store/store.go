@@ -0,0 +1,31 @@
+package store
+
+import (
+	"time"
+
+	"github.com/viasnake/arbiter/internal/domain"
+)
+
+// Store is the persistence contract app.Service relies on. MemoryStore is the
+// default, in-process implementation; other backends (e.g. SQLiteStore) plug
+// in via config.StoreConfig.Type without changing call sites.
+type Store interface {
+	GetIdempotency(tenantID, eventID string) (domain.ResponsePlan, bool)
+	PutIdempotency(tenantID, eventID string, plan domain.ResponsePlan)
+
+	GetRoomState(tenantID, roomID string) RoomState
+	PutPendingGeneration(p PendingGeneration)
+	ConsumePendingGeneration(tenantID, actionID string, at time.Time) (PendingGeneration, bool)
+
+	TenantRateCount(tenantID string, minuteBucket int64) int
+	IncrementTenantRate(tenantID string, minuteBucket int64)
+
+	// TokenBucketTake and SlidingWindowAllow back internal/ratelimit's
+	// per-scope limiters. Unlike TenantRateCount's fixed-minute bucket,
+	// both hold their own continuously-moving state keyed by an arbitrary
+	// caller-chosen string, so a backend can refill/evict it correctly
+	// regardless of wall-clock minute boundaries and share it safely
+	// across replicas.
+	TokenBucketTake(key string, ratePerSec float64, burst, cost int, now time.Time) (allowed bool, retryAfter time.Duration)
+	SlidingWindowAllow(key string, window time.Duration, limit int, now time.Time) bool
+}